@@ -6,13 +6,25 @@ require (
 	github.com/beevik/ntp v0.2.0
 	github.com/coreos/go-systemd v0.0.0-20191104093116-d3cd4ed1dbcf
 	github.com/ema/qdisc v0.0.0-20200603082823-62d0308e3e00
+	github.com/filecoin-project/go-address v0.0.2-0.20200504173055-8b6f2fb2b3ef
+	github.com/filecoin-project/go-bitfield v0.0.2-0.20200629135455-587b27927d38
+	github.com/filecoin-project/go-fil-markets v0.3.2-0.20200702145639-4034a18364e4
 	github.com/filecoin-project/go-jsonrpc v0.1.1-0.20200602181149-522144ab4e24
 	github.com/filecoin-project/lotus v0.4.1
+	github.com/filecoin-project/sector-storage v0.0.0-20200630180318-4c1968f62a8f
+	github.com/filecoin-project/specs-actors v0.6.2-0.20200702170846-2cd72643a5cf
 	github.com/godbus/dbus v0.0.0-20190402143921-271e53dc4968
+	github.com/golang/protobuf v1.4.2
+	github.com/golang/snappy v0.0.1
+	github.com/gorilla/websocket v1.4.2
 	github.com/hodgesds/perf-utils v0.0.8
+	github.com/ipfs/go-cid v0.0.6
+	github.com/klauspost/compress v1.11.0
 	github.com/libp2p/go-libp2p-core v0.6.0
 	github.com/mattn/go-xmlrpc v0.0.3
 	github.com/mdlayher/wifi v0.0.0-20190303161829-b1436901ddee
+	github.com/mitchellh/mapstructure v1.1.2
+	github.com/multiformats/go-multiaddr v0.2.2
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.7.1
 	github.com/prometheus/client_model v0.2.0
@@ -25,6 +37,6 @@ require (
 	github.com/stretchr/testify v1.6.1
 	go.uber.org/zap v1.15.0
 	golang.org/x/sys v0.0.0-20200615200032-f1bc736245b1
-	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+	google.golang.org/protobuf v1.24.0
 	k8s.io/klog v1.0.0
 )
@@ -74,7 +74,10 @@ func newFildrCmd(version, gitCommit, buildTime string) *cobra.Command {
 				klog.InitFlags(klogFlagSet)
 				_ = klogFlagSet.Parse(klogOpts)
 
-				options := runner2.Options{}
+				options := runner2.Options{
+					Version:   version,
+					GitCommit: gitCommit,
+				}
 
 				runner, err := runner2.NewRunner(ctx, logger, options)
 				if err != nil {
@@ -35,6 +35,7 @@ func newInitializationCmd() *cobra.Command {
 	initializationCmd.Flags().StringP("gateway.instance", "", "", "config gateway instance")
 	initializationCmd.Flags().DurationP("gateway.evaluation", "", time.Second*5, "config gateway evaluation")
 	initializationCmd.Flags().StringP("gateway.url", "", "https://api.fildr.com/fildr-miner", "config gateway url")
+	initializationCmd.Flags().StringP("gateway.protocol", "", "pushgateway", "config gateway protocol, pushgateway or remote_write")
 
 	return initializationCmd
 }
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// 推送认证，令牌按 token-file、token-env、token 的顺序读取，
+// 避免在配置文件中明文保存令牌
+type Auth struct {
+	// 令牌所在的请求头，pushgateway 默认为 blade-auth，remote_write 默认为 Authorization
+	Header string `mapstructure:"header"`
+	// 令牌前缀，默认为 Bearer，设置为 none 时只发送令牌
+	Scheme    string `mapstructure:"scheme"`
+	Token     string `mapstructure:"token"`
+	TokenFile string `mapstructure:"token-file"`
+	TokenEnv  string `mapstructure:"token-env"`
+	// HTTP basic 认证
+	Username     string `mapstructure:"username"`
+	Password     string `mapstructure:"password"`
+	PasswordFile string `mapstructure:"password-file"`
+	TLS          TLS    `mapstructure:"tls"`
+}
+
+// basic 认证写入 Authorization，令牌也使用该请求头时会覆盖 basic 认证。
+// token 为输出配置中的令牌，defaultHeader 为未配置 header 时令牌所在的请求头
+func (a Auth) Validate(token, defaultHeader string) error {
+	if a.Username == "" {
+		return nil
+	}
+	header := a.Header
+	if header == "" {
+		header = defaultHeader
+	}
+	if !strings.EqualFold(header, "Authorization") {
+		return nil
+	}
+	if a.Token != "" || a.TokenFile != "" || a.TokenEnv != "" || token != "" {
+		return fmt.Errorf("auth username and token both use the Authorization header, remove one of them or set auth.header for the token")
+	}
+	return nil
+}
+
+// 客户端证书和自定义 CA
+type TLS struct {
+	CAFile             string `mapstructure:"ca-file"`
+	CertFile           string `mapstructure:"cert-file"`
+	KeyFile            string `mapstructure:"key-file"`
+	ServerName         string `mapstructure:"server-name"`
+	InsecureSkipVerify bool   `mapstructure:"insecure-skip-verify"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CAFile != "" || t.CertFile != "" || t.KeyFile != "" || t.ServerName != "" || t.InsecureSkipVerify
+}
+
+// 按配置生成 tls.Config，未配置时返回 nil 使用系统默认设置
+func (t TLS) ClientConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		b, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
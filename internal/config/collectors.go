@@ -0,0 +1,33 @@
+package config
+
+import "github.com/mitchellh/mapstructure"
+
+// 收集器配置，以收集器名称为键，例如:
+//
+//	[collectors.systemd]
+//	  enable = true
+//	  unit-include = "lotus.+"
+type Collectors map[string]Collector
+
+type Collector map[string]interface{}
+
+// 收集器是否启用，未配置 enable 时使用收集器的默认值
+func (c Collector) Enabled(def bool) bool {
+	if enable, ok := c["enable"].(bool); ok {
+		return enable
+	}
+	return def
+}
+
+// 将收集器配置解码到选项结构体，未配置的字段保留原值
+func (c Collector) Decode(opts interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       decodeHook(),
+		WeaklyTypedInput: true,
+		Result:           opts,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(map[string]interface{}(c))
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectorsEnabled(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+[collectors]
+  [collectors.systemd]
+    enable = true
+  [collectors.cpu]
+    enable = false
+`)))
+
+	c := Config{}
+	require.NoError(t, v.Unmarshal(&c))
+
+	assert.True(t, c.Collectors["systemd"].Enabled(false))
+	assert.False(t, c.Collectors["cpu"].Enabled(true))
+	assert.True(t, c.Collectors["meminfo"].Enabled(true))
+	assert.False(t, c.Collectors["ntp"].Enabled(false))
+}
+
+func TestCollectorDecode(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+[collectors]
+  [collectors.filesystem]
+    enable = true
+    mount-timeout = "10s"
+  [collectors.ipvs]
+    backend-labels = "local_address,local_port"
+`)))
+
+	c := Config{}
+	require.NoError(t, v.Unmarshal(&c))
+
+	var fs struct {
+		IgnoredFSTypes string        `mapstructure:"ignored-fs-types"`
+		MountTimeout   time.Duration `mapstructure:"mount-timeout"`
+	}
+	fs.IgnoredFSTypes = "^tmpfs$"
+	require.NoError(t, c.Collectors["filesystem"].Decode(&fs))
+	assert.Equal(t, 10*time.Second, fs.MountTimeout)
+	assert.Equal(t, "^tmpfs$", fs.IgnoredFSTypes)
+
+	var ipvs struct {
+		BackendLabels []string `mapstructure:"backend-labels"`
+	}
+	require.NoError(t, c.Collectors["ipvs"].Decode(&ipvs))
+	assert.Equal(t, []string{"local_address", "local_port"}, ipvs.BackendLabels)
+
+	var missing struct {
+		Directory string `mapstructure:"directory"`
+	}
+	missing.Directory = "/var/lib/fildr"
+	require.NoError(t, c.Collectors["textfile"].Decode(&missing))
+	assert.Equal(t, "/var/lib/fildr", missing.Directory)
+}
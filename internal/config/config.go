@@ -7,26 +7,52 @@ import (
 )
 
 type Config struct {
-	Gateway Gateway `mapstructure:"gateway"`
-	Lotus   Lotus   `mapstructure:"lotus"`
+	Gateway    Gateway    `mapstructure:"gateway"`
+	Web        Web        `mapstructure:"web"`
+	Lotus      Lotus      `mapstructure:"lotus"`
+	Collectors Collectors `mapstructure:"collectors"`
+	Outputs    []Output   `mapstructure:"outputs"`
+	Pledge     Pledge     `mapstructure:"pledge"`
+	Logs       Logs       `mapstructure:"logs"`
 }
 
 var cfg = Config{}
 
+// 程序目录 ~/.fildr
+func Dir() (string, error) {
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return user.HomeDir + "/.fildr", nil
+}
+
 // 加载配置文件
 func LoadConfig() error {
-	user, err := user.Current()
+	dir, err := Dir()
 	if err != nil {
 		return err
 	}
-	path := user.HomeDir + "/.fildr/config.toml"
+	path := dir + "/config.toml"
 	viper.SetConfigType("toml")
 	viper.SetConfigFile(path)
+	viper.SetDefault("gateway.spool.enable", true)
+	viper.SetDefault("gateway.spool.max-bytes", DefaultSpoolMaxBytes)
+	viper.SetDefault("gateway.spool.max-age", DefaultSpoolMaxAge)
+	viper.SetDefault("pledge.dry-run", true)
+	viper.SetDefault("pledge.interval", DefaultPledgeInterval)
+	viper.SetDefault("pledge.max-sealing", DefaultPledgeMaxSealing)
+	viper.SetDefault("pledge.max-per-hour", DefaultPledgeMaxPerHour)
+	viper.SetDefault("pledge.scan-sectors", DefaultPledgeScanSectors)
+	viper.SetDefault("logs.default-signatures", true)
+	viper.SetDefault("logs.ship.batch-size", DefaultLogShipBatchSize)
+	viper.SetDefault("logs.ship.interval", DefaultLogShipInterval)
+	viper.SetDefault("logs.ship.max-buffer", DefaultLogShipMaxBuffer)
 
 	if err = viper.ReadInConfig(); err != nil {
 		return err
 	}
-	return viper.Unmarshal(&cfg)
+	return viper.Unmarshal(&cfg, viper.DecodeHook(decodeHook()))
 }
 
 func Get() Config {
@@ -42,11 +68,10 @@ func Get() Config {
 }
 
 func InitializationConfig() error {
-	user, err := user.Current()
+	path, err := Dir()
 	if err != nil {
 		return err
 	}
-	path := user.HomeDir + "/.fildr"
 	_, err = os.Stat(path)
 	if err != nil {
 		err = os.Mkdir(path, os.ModePerm)
@@ -59,13 +84,23 @@ func InitializationConfig() error {
 	viper.SetConfigFile(path + `/config.toml`)
 
 	viper.Set("gateway.url", viper.GetString("gateway.url"))
+	viper.Set("gateway.protocol", viper.GetString("gateway.protocol"))
 	viper.Set("gateway.token", viper.GetString("gateway.token"))
 	viper.Set("gateway.instance", viper.GetString("gateway.instance"))
 	viper.Set("gateway.evaluation", viper.GetDuration("gateway.evaluation"))
+	viper.Set("gateway.spool.enable", true)
+	viper.Set("gateway.spool.max-bytes", DefaultSpoolMaxBytes)
+	viper.Set("gateway.spool.max-age", DefaultSpoolMaxAge)
+
+	viper.Set("web.enable", false)
+	viper.Set("web.address", ":9110")
 
 	viper.Set("lotus.daemon.enable", false)
 	viper.Set("lotus.daemon.ip", "127.0.0.1")
 	viper.Set("lotus.daemon.port", 1234)
+	viper.Set("lotus.miner.enable", false)
+	viper.Set("lotus.miner.ip", "127.0.0.1")
+	viper.Set("lotus.miner.port", 2345)
 
 	return viper.WriteConfig()
 }
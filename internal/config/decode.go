@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"reflect"
+	"time"
+)
+
+// 配置文件和收集器选项使用的解码方式
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		secondsToDurationHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+// 没有单位的时长按秒解析，例如 evaluation = 15 为 15s，否则会被解析为 15ns
+func secondsToDurationHook(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if t != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	v := reflect.ValueOf(data)
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return time.Duration(v.Int()) * time.Second, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return time.Duration(v.Uint()) * time.Second, nil
+	case reflect.Float32, reflect.Float64:
+		return time.Duration(v.Float() * float64(time.Second)), nil
+	}
+	return data, nil
+}
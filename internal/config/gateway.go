@@ -2,9 +2,45 @@ package config
 
 import "time"
 
+const (
+	ProtocolPushgateway = "pushgateway"
+	ProtocolRemoteWrite = "remote_write"
+)
+
+const (
+	DefaultEvaluation    = 5 * time.Second
+	DefaultSpoolMaxBytes = 64 << 20
+	DefaultSpoolMaxAge   = 24 * time.Hour
+)
+
 type Gateway struct {
-	Url        string        `mapstructure:"url"`
-	Token      string        `mapstructure:"token"`
-	Instance   string        `mapstructure:"instance"`
-	Evaluation time.Duration `mapstructure:"evaluation"`
+	Url        string                   `mapstructure:"url"`
+	Protocol   string                   `mapstructure:"protocol"`
+	Token      string                   `mapstructure:"token"`
+	Instance   string                   `mapstructure:"instance"`
+	Evaluation time.Duration            `mapstructure:"evaluation"`
+	Intervals  map[string]time.Duration `mapstructure:"intervals"`
+	Spool      Spool                    `mapstructure:"spool"`
+	Auth       Auth                     `mapstructure:"auth"`
+	// 推送请求体的压缩方式：none、gzip、zstd
+	Compression string `mapstructure:"compression"`
+}
+
+// 推送失败时的本地缓存，dir 为空时使用 ~/.fildr/spool
+type Spool struct {
+	Enable   bool          `mapstructure:"enable"`
+	Dir      string        `mapstructure:"dir"`
+	MaxBytes int64         `mapstructure:"max-bytes"`
+	MaxAge   time.Duration `mapstructure:"max-age"`
+}
+
+// 获取命名空间的推送间隔，未单独配置时使用 evaluation，小于1秒的配置视为无效
+func (g Gateway) Interval(namespace string) time.Duration {
+	if interval, ok := g.Intervals[namespace]; ok && interval >= time.Second {
+		return interval
+	}
+	if g.Evaluation >= time.Second {
+		return g.Evaluation
+	}
+	return DefaultEvaluation
 }
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGatewayInterval(t *testing.T) {
+	g := Gateway{
+		Evaluation: 15 * time.Second,
+		Intervals: map[string]time.Duration{
+			"lotus": time.Minute,
+			"bad":   5,
+		},
+	}
+
+	assert.Equal(t, time.Minute, g.Interval("lotus"))
+	assert.Equal(t, 15*time.Second, g.Interval("node"))
+	assert.Equal(t, 15*time.Second, g.Interval("bad"))
+	assert.Equal(t, DefaultEvaluation, Gateway{Evaluation: 5}.Interval("node"))
+}
+
+func TestGatewayDurationSeconds(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+[gateway]
+  evaluation = 15
+  [gateway.intervals]
+    lotus = "1m"
+    node = 30
+    logs = 0.5
+`)))
+
+	c := Config{}
+	require.NoError(t, v.Unmarshal(&c, viper.DecodeHook(decodeHook())))
+
+	// 没有单位的时长按秒解析
+	assert.Equal(t, 15*time.Second, c.Gateway.Evaluation)
+	assert.Equal(t, time.Minute, c.Gateway.Interval("lotus"))
+	assert.Equal(t, 30*time.Second, c.Gateway.Interval("node"))
+	assert.Equal(t, 500*time.Millisecond, c.Gateway.Intervals["logs"])
+}
+
+func TestEffectiveOutputs(t *testing.T) {
+	c := Config{Gateway: Gateway{Url: "http://gw", Token: "t", Evaluation: 15 * time.Second}}
+	outputs := c.EffectiveOutputs()
+	assert.Equal(t, []Output{{Name: "gateway", Type: ProtocolPushgateway, Url: "http://gw", Token: "t"}}, outputs)
+	assert.Equal(t, 15*time.Second, outputs[0].PushInterval(c.Gateway, "node"))
+	assert.True(t, outputs[0].Accepts("node"))
+
+	c.Outputs = []Output{{Type: OutputStdout, Interval: time.Minute, Namespaces: []string{"lotus"}}}
+	outputs = c.EffectiveOutputs()
+	assert.Len(t, outputs, 1)
+	assert.Equal(t, time.Minute, outputs[0].PushInterval(c.Gateway, "lotus"))
+	assert.False(t, outputs[0].Accepts("node"))
+
+	assert.Empty(t, Config{}.EffectiveOutputs())
+}
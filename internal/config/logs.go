@@ -0,0 +1,46 @@
+package config
+
+import "time"
+
+const (
+	DefaultLogShipBatchSize = 500
+	DefaultLogShipInterval  = 10 * time.Second
+	DefaultLogShipMaxBuffer = 10000
+)
+
+// lotus 日志采集，统计日志级别、错误和已知故障特征，可选将日志行发送到外部服务
+type Logs struct {
+	Enable  bool        `mapstructure:"enable"`
+	Sources []LogSource `mapstructure:"sources"`
+	// 关闭后只使用 signatures 中配置的故障特征
+	DefaultSignatures bool           `mapstructure:"default-signatures"`
+	Signatures        []LogSignature `mapstructure:"signatures"`
+	Ship              LogShip        `mapstructure:"ship"`
+}
+
+// 日志来源，path 和 unit 二选一，name 作为指标的 source 标签
+type LogSource struct {
+	Name string `mapstructure:"name"`
+	// 日志文件路径，按轮转后的新文件继续读取
+	Path string `mapstructure:"path"`
+	// journald 单元，通过 journalctl 读取
+	Unit string `mapstructure:"unit"`
+}
+
+// 故障特征，pattern 为匹配整行日志的正则表达式
+type LogSignature struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+// 日志行以 JSON Lines 格式批量 POST 到 url，url 为空时不发送
+type LogShip struct {
+	Url  string `mapstructure:"url"`
+	Auth Auth   `mapstructure:"auth"`
+	// 只发送这些级别的日志，为空时全部发送
+	Levels    []string      `mapstructure:"levels"`
+	BatchSize int           `mapstructure:"batch-size"`
+	Interval  time.Duration `mapstructure:"interval"`
+	// 发送失败时最多缓存的行数，超出后丢弃最早的日志
+	MaxBuffer int `mapstructure:"max-buffer"`
+}
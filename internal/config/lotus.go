@@ -1,11 +1,155 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
+// lotus 接口调用的默认超时时间
+const DefaultLotusTimeout = 30 * time.Second
+
 type Lotus struct {
 	Daemon Daemon `mapstructure:"daemon"`
+	Miner  Miner  `mapstructure:"miner"`
+	// 同时监控多个 daemon 和 miner，配置后忽略 [lotus.daemon] 和 [lotus.miner]
+	Daemons []Daemon `mapstructure:"daemons"`
+	Miners  []Miner  `mapstructure:"miners"`
+	// wss 连接使用的证书设置，对所有 lotus 连接生效
+	TLS TLS `mapstructure:"tls"`
 }
 
 type Daemon struct {
-	Enable bool   `mapstructure:"enable"`
-	Ip     string `mapstructure:"ip"`
-	Port   int    `mapstructure:"port"`
+	// [lotus.daemon] 默认关闭，[[lotus.daemons]] 中的每一项默认开启
+	Enable *bool `mapstructure:"enable"`
+	// 多个 daemon 时作为指标的 node 标签
+	Name     string `mapstructure:"name"`
+	Endpoint `mapstructure:",squash"`
+}
+
+// lotus-miner 的指标同时需要 daemon 的链上数据
+type Miner struct {
+	// [lotus.miner] 默认关闭，[[lotus.miners]] 中的每一项默认开启
+	Enable *bool `mapstructure:"enable"`
+	// 多个 miner 时作为指标的 node 标签
+	Name string `mapstructure:"name"`
+	// 获取链上数据的 daemon 名称，默认为第一个 daemon
+	Daemon   string `mapstructure:"daemon"`
+	Endpoint `mapstructure:",squash"`
+}
+
+// lotus 接口地址，按 api-info、环境变量（FULLNODE_API_INFO、MINER_API_INFO）、repo 目录、ip 和 port 的顺序选择
+type Endpoint struct {
+	Ip   string `mapstructure:"ip"`
+	Port int    `mapstructure:"port"`
+	// token:multiaddr 格式，与 lotus 的 FULLNODE_API_INFO 相同
+	ApiInfo string `mapstructure:"api-info"`
+	// lotus repo 目录，读取其中的 api 和 token 文件
+	Repo      string `mapstructure:"repo"`
+	Token     string `mapstructure:"token"`
+	TokenFile string `mapstructure:"token-file"`
+	// 接口版本 v0 或 v1，默认为 v0
+	ApiVersion string `mapstructure:"api-version"`
+	// 一次采集中调用接口的超时时间，默认为 30s
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// 未配置 timeout 时使用默认值
+func (e Endpoint) CallTimeout() time.Duration {
+	if e.Timeout <= 0 {
+		return DefaultLotusTimeout
+	}
+	return e.Timeout
+}
+
+// 是否使用 [[lotus.daemons]] 和 [[lotus.miners]]
+func (l Lotus) Multiple() bool {
+	return len(l.Daemons) > 0 || len(l.Miners) > 0
+}
+
+// 实际监控的 daemon，未配置 [[lotus.daemons]] 时为开启的 [lotus.daemon]
+func (l Lotus) EffectiveDaemons() []Daemon {
+	if !l.Multiple() {
+		if enabled(l.Daemon.Enable, false) {
+			return []Daemon{l.Daemon}
+		}
+		return nil
+	}
+	var daemons []Daemon
+	for _, d := range l.Daemons {
+		if enabled(d.Enable, true) {
+			daemons = append(daemons, d)
+		}
+	}
+	return daemons
+}
+
+// 实际监控的 miner，未配置 [[lotus.miners]] 时为开启的 [lotus.miner]
+func (l Lotus) EffectiveMiners() []Miner {
+	if !l.Multiple() {
+		if enabled(l.Miner.Enable, false) {
+			return []Miner{l.Miner}
+		}
+		return nil
+	}
+	var miners []Miner
+	for _, m := range l.Miners {
+		if enabled(m.Enable, true) {
+			miners = append(miners, m)
+		}
+	}
+	return miners
+}
+
+// 未配置 enable 时使用默认值
+func enabled(enable *bool, def bool) bool {
+	if enable == nil {
+		return def
+	}
+	return *enable
+}
+
+// miner 获取链上数据使用的 daemon，[lotus.miner] 始终使用 [lotus.daemon]
+func (l Lotus) MinerDaemon(m Miner) (Daemon, error) {
+	if !l.Multiple() {
+		return l.Daemon, nil
+	}
+	for _, d := range l.EffectiveDaemons() {
+		if m.Daemon == "" || d.Name == m.Daemon {
+			return d, nil
+		}
+	}
+	if m.Daemon == "" {
+		return Daemon{}, fmt.Errorf("lotus miner %s has no daemon", m.Name)
+	}
+	for _, d := range l.Daemons {
+		if d.Name == m.Daemon {
+			return Daemon{}, fmt.Errorf("lotus miner %s: daemon %s is disabled", m.Name, m.Daemon)
+		}
+	}
+	return Daemon{}, fmt.Errorf("lotus miner %s: daemon %s not found", m.Name, m.Daemon)
+}
+
+// 多个 daemon 或 miner 时名称不能为空且不能重复
+func (l Lotus) Validate() error {
+	names := make(map[string]bool)
+	for _, d := range l.Daemons {
+		if d.Name == "" {
+			return fmt.Errorf("lotus daemons require name")
+		}
+		if names[d.Name] {
+			return fmt.Errorf("duplicate lotus daemon name %s", d.Name)
+		}
+		names[d.Name] = true
+	}
+	names = make(map[string]bool)
+	for _, m := range l.Miners {
+		if m.Name == "" {
+			return fmt.Errorf("lotus miners require name")
+		}
+		if names[m.Name] {
+			return fmt.Errorf("duplicate lotus miner name %s", m.Name)
+		}
+		names[m.Name] = true
+	}
+	return nil
 }
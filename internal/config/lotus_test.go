@@ -0,0 +1,65 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLotusEffectiveEndpoints(t *testing.T) {
+	l := Lotus{
+		Daemon: Daemon{Enable: boolPtr(true), Endpoint: Endpoint{Ip: "127.0.0.1", Port: 1234}},
+		Miner:  Miner{Endpoint: Endpoint{Ip: "127.0.0.1", Port: 2345}},
+	}
+	assert.Equal(t, []Daemon{l.Daemon}, l.EffectiveDaemons())
+	assert.Empty(t, l.EffectiveMiners())
+	d, err := l.MinerDaemon(l.Miner)
+	assert.NoError(t, err)
+	assert.Equal(t, l.Daemon, d)
+
+	l.Daemons = []Daemon{{Name: "a"}, {Name: "b"}}
+	l.Miners = []Miner{{Name: "m1"}, {Name: "m2", Daemon: "b"}, {Name: "m3", Daemon: "c"}}
+	assert.Equal(t, l.Daemons, l.EffectiveDaemons())
+	assert.Equal(t, l.Miners, l.EffectiveMiners())
+
+	d, err = l.MinerDaemon(l.Miners[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "a", d.Name)
+	d, err = l.MinerDaemon(l.Miners[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "b", d.Name)
+	_, err = l.MinerDaemon(l.Miners[2])
+	assert.Error(t, err)
+}
+
+func TestLotusDisabledEntries(t *testing.T) {
+	l := Lotus{
+		Daemons: []Daemon{{Name: "a", Enable: boolPtr(false)}, {Name: "b"}, {Name: "c", Enable: boolPtr(true)}},
+		Miners:  []Miner{{Name: "m1", Enable: boolPtr(false)}, {Name: "m2"}, {Name: "m3", Daemon: "a"}},
+	}
+	// 未配置 enable 的项默认开启
+	assert.Equal(t, []Daemon{l.Daemons[1], l.Daemons[2]}, l.EffectiveDaemons())
+	assert.Equal(t, []Miner{l.Miners[1], l.Miners[2]}, l.EffectiveMiners())
+
+	// 默认使用第一个开启的 daemon
+	d, err := l.MinerDaemon(l.Miners[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "b", d.Name)
+	_, err = l.MinerDaemon(l.Miners[2])
+	assert.Error(t, err)
+
+	// [lotus.daemon] 和 [lotus.miner] 默认关闭
+	assert.Empty(t, Lotus{}.EffectiveDaemons())
+	assert.Empty(t, Lotus{}.EffectiveMiners())
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestLotusValidate(t *testing.T) {
+	assert.NoError(t, Lotus{}.Validate())
+	assert.NoError(t, Lotus{Daemons: []Daemon{{Name: "a"}}, Miners: []Miner{{Name: "a"}}}.Validate())
+	assert.Error(t, Lotus{Daemons: []Daemon{{}}}.Validate())
+	assert.Error(t, Lotus{Daemons: []Daemon{{Name: "a"}, {Name: "a"}}}.Validate())
+	assert.Error(t, Lotus{Miners: []Miner{{Name: "m"}, {Name: "m"}}}.Validate())
+}
@@ -0,0 +1,79 @@
+package config
+
+import "time"
+
+const (
+	OutputPushgateway = "pushgateway"
+	OutputRemoteWrite = "remote_write"
+	OutputFile        = "file"
+	OutputStdout      = "stdout"
+
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// 指标输出目标，对应配置文件中的 [[outputs]]，未配置时使用 [gateway]
+type Output struct {
+	Name  string `mapstructure:"name"`
+	Type  string `mapstructure:"type"`
+	Url   string `mapstructure:"url"`
+	Token string `mapstructure:"token"`
+	Auth  Auth   `mapstructure:"auth"`
+	// 请求体压缩方式，仅 pushgateway 支持 gzip、zstd，remote_write 固定使用 snappy
+	Compression string `mapstructure:"compression"`
+	// file 输出的文件路径
+	Path string `mapstructure:"path"`
+	// 推送间隔，为空时使用 gateway 的推送间隔
+	Interval time.Duration `mapstructure:"interval"`
+	// 只输出这些命名空间，为空时输出全部
+	Namespaces []string `mapstructure:"namespaces"`
+	// 按指标名过滤的正则表达式
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+	// 按标签过滤，标签值需匹配对应的正则表达式
+	Match map[string]string `mapstructure:"match"`
+}
+
+// 实际使用的输出目标，未配置 [[outputs]] 时由 [gateway] 生成
+func (c Config) EffectiveOutputs() []Output {
+	if len(c.Outputs) > 0 {
+		return c.Outputs
+	}
+	if c.Gateway.Url == "" {
+		return nil
+	}
+	protocol := c.Gateway.Protocol
+	if protocol == "" {
+		protocol = ProtocolPushgateway
+	}
+	return []Output{{
+		Name:        "gateway",
+		Type:        protocol,
+		Url:         c.Gateway.Url,
+		Token:       c.Gateway.Token,
+		Auth:        c.Gateway.Auth,
+		Compression: c.Gateway.Compression,
+	}}
+}
+
+// 输出的推送间隔，未配置或小于 1 秒时使用 gateway 对该命名空间的推送间隔
+func (o Output) PushInterval(g Gateway, namespace string) time.Duration {
+	if o.Interval >= time.Second {
+		return o.Interval
+	}
+	return g.Interval(namespace)
+}
+
+// 是否输出该命名空间的指标
+func (o Output) Accepts(namespace string) bool {
+	if len(o.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range o.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
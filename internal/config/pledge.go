@@ -0,0 +1,35 @@
+package config
+
+import "time"
+
+const (
+	DefaultPledgeInterval   = 10 * time.Minute
+	DefaultPledgeMaxSealing = 2
+	DefaultPledgeMaxPerHour = 1
+	// 每次检查最多查询状态的扇区数
+	DefaultPledgeScanSectors = 500
+)
+
+// 自动质押扇区，默认只记录决策不实际质押
+type Pledge struct {
+	Enable   bool          `mapstructure:"enable"`
+	DryRun   bool          `mapstructure:"dry-run"`
+	Interval time.Duration `mapstructure:"interval"`
+	// 质押的 miner 名称，为空时为第一个 miner
+	Miner string `mapstructure:"miner"`
+	// 封装中的扇区数达到该值时不再质押
+	MaxSealing int `mapstructure:"max-sealing"`
+	// 从编号最大的扇区开始最多查询的扇区数，封装中的扇区通常是最新的扇区
+	ScanSectors int `mapstructure:"scan-sectors"`
+	// 每个存储路径的剩余空间都需大于 min-free-bytes
+	StoragePaths []string `mapstructure:"storage-paths"`
+	MinFreeBytes uint64   `mapstructure:"min-free-bytes"`
+	// 有空闲 CPU 的 worker 数
+	MinAvailableWorkers int `mapstructure:"min-available-workers"`
+	// worker 钱包的最低余额，单位 FIL
+	MinBalance string `mapstructure:"min-balance"`
+	// 每小时最多质押次数，dry-run 同样计数
+	MaxPerHour int `mapstructure:"max-per-hour"`
+	// 决策日志，为空时使用 ~/.fildr/pledge-audit.log
+	AuditLog string `mapstructure:"audit-log"`
+}
@@ -0,0 +1,6 @@
+package config
+
+type Web struct {
+	Enable  bool   `mapstructure:"enable"`
+	Address string `mapstructure:"address"`
+}
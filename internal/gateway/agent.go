@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	pushDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: agentNamespace,
+		Subsystem: "push",
+		Name:      "duration_seconds",
+		Help:      "Duration of push requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"output"})
+	pushRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: agentNamespace,
+		Subsystem: "push",
+		Name:      "requests_total",
+		Help:      "Number of push requests by HTTP status code, code is error when no response was received.",
+	}, []string{"output", "code"})
+	pushSentBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: agentNamespace,
+		Subsystem: "push",
+		Name:      "sent_bytes_total",
+		Help:      "Number of request body bytes successfully pushed.",
+	}, []string{"output"})
+	pushLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: agentNamespace,
+		Subsystem: "push",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful push.",
+	}, []string{"output"})
+	collectorFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: agentNamespace,
+		Subsystem: "collector",
+		Name:      "registration_failures_total",
+		Help:      "Number of collectors that failed to initialize when their module started.",
+	}, []string{"namespace", "collector"})
+	tickLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: agentNamespace,
+		Subsystem: "timewheel",
+		Name:      "tick_lag_seconds",
+		Help:      "Delay of the last one second time wheel tick.",
+	})
+)
+
+var buildVersion, buildCommit string
+
+// 设置版本信息，由 fildr_agent_build_info 指标输出
+func SetBuildInfo(version, commit string) {
+	buildVersion = version
+	buildCommit = commit
+}
+
+// 记录模块启动时初始化失败的收集器
+func RegistryFailed(namespace string, name string) {
+	collectorFailures.WithLabelValues(namespace, name).Inc()
+}
+
+// 注册 agent 自身指标
+func registerAgent() {
+	Registry(agentNamespace, "runtime", newRuntimeCollector())
+	Registry(agentNamespace, "output", newOutputCollector(outputs))
+	Registry(agentNamespace, "collector", vecCollector{collectorFailures})
+	Registry(agentNamespace, "timewheel", vecCollector{tickLag})
+}
+
+// 将 client_golang 的指标适配为 Collector
+type vecCollector []prometheus.Collector
+
+func (v vecCollector) Update(ch chan<- prometheus.Metric) error {
+	for _, c := range v {
+		c.Collect(ch)
+	}
+	return nil
+}
+
+// 每次推送的统计，replay 的推送同样计入
+type pushStats struct {
+	output string
+
+	mu      sync.Mutex
+	raw     int
+	encoded int
+}
+
+// 记录请求体压缩前后的大小
+func (s *pushStats) observePayload(raw, encoded int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw = raw
+	s.encoded = encoded
+}
+
+func (s *pushStats) payload() (raw, encoded int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.raw, s.encoded
+}
+
+func (s *pushStats) observePush(begin time.Time, code int, size int, err error) {
+	if s == nil {
+		return
+	}
+	pushDuration.WithLabelValues(s.output).Observe(time.Since(begin).Seconds())
+	label := "error"
+	if code != 0 {
+		label = strconv.Itoa(code)
+	}
+	pushRequests.WithLabelValues(s.output, label).Inc()
+	if err == nil {
+		pushSentBytes.WithLabelValues(s.output).Add(float64(size))
+		pushLastSuccess.WithLabelValues(s.output).Set(float64(time.Now().Unix()))
+	}
+}
+
+// 输出推送状态的收集器
+type outputCollector struct {
+	authFailuresDesc *prometheus.Desc
+	authFailingDesc  *prometheus.Desc
+	payloadDesc      *prometheus.Desc
+	ratioDesc        *prometheus.Desc
+	outputs          []*output
+}
+
+func newOutputCollector(outputs []*output) *outputCollector {
+	return &outputCollector{
+		authFailuresDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "auth", "consecutive_failures"),
+			"Number of consecutive pushes rejected with 401 or 403.",
+			[]string{"output"}, nil,
+		),
+		authFailingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "auth", "failing"),
+			"Whether authentication keeps failing for the output.",
+			[]string{"output"}, nil,
+		),
+		payloadDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "push", "payload_bytes"),
+			"Size of the last push request body before and after compression.",
+			[]string{"output", "stage"}, nil,
+		),
+		ratioDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "push", "compression_ratio"),
+			"Uncompressed size divided by compressed size of the last push request body.",
+			[]string{"output"}, nil,
+		),
+		outputs: outputs,
+	}
+}
+
+func (c *outputCollector) Update(ch chan<- prometheus.Metric) error {
+	pushDuration.Collect(ch)
+	pushRequests.Collect(ch)
+	pushSentBytes.Collect(ch)
+	pushLastSuccess.Collect(ch)
+
+	for _, o := range c.outputs {
+		if o.auth != nil {
+			failures := o.auth.consecutiveFailures()
+			var failing float64
+			if failures >= authFailureThreshold {
+				failing = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.authFailuresDesc, prometheus.GaugeValue, float64(failures), o.name)
+			ch <- prometheus.MustNewConstMetric(c.authFailingDesc, prometheus.GaugeValue, failing, o.name)
+		}
+
+		if o.stats != nil {
+			raw, encoded := o.stats.payload()
+			if encoded == 0 {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.payloadDesc, prometheus.GaugeValue, float64(raw), o.name, "uncompressed")
+			ch <- prometheus.MustNewConstMetric(c.payloadDesc, prometheus.GaugeValue, float64(encoded), o.name, "compressed")
+			ch <- prometheus.MustNewConstMetric(c.ratioDesc, prometheus.GaugeValue, float64(raw)/float64(encoded), o.name)
+		}
+	}
+	return nil
+}
+
+// 版本、协程数和内存使用
+type runtimeCollector struct {
+	buildInfoDesc  *prometheus.Desc
+	goroutinesDesc *prometheus.Desc
+	memoryDesc     *prometheus.Desc
+}
+
+func newRuntimeCollector() *runtimeCollector {
+	return &runtimeCollector{
+		buildInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "", "build_info"),
+			"A metric with a constant '1' value labeled by version, commit and goversion.",
+			[]string{"version", "commit", "goversion"}, nil,
+		),
+		goroutinesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "", "goroutines"),
+			"Number of goroutines that currently exist.",
+			nil, nil,
+		),
+		memoryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(agentNamespace, "memory", "bytes"),
+			"Memory obtained from the OS and allocated heap bytes.",
+			[]string{"type"}, nil,
+		),
+	}
+}
+
+func (c *runtimeCollector) Update(ch chan<- prometheus.Metric) error {
+	ch <- prometheus.MustNewConstMetric(c.buildInfoDesc, prometheus.GaugeValue, 1, buildVersion, buildCommit, runtime.Version())
+	ch <- prometheus.MustNewConstMetric(c.goroutinesDesc, prometheus.GaugeValue, float64(runtime.NumGoroutine()))
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	ch <- prometheus.MustNewConstMetric(c.memoryDesc, prometheus.GaugeValue, float64(ms.Sys), "sys")
+	ch <- prometheus.MustNewConstMetric(c.memoryDesc, prometheus.GaugeValue, float64(ms.HeapAlloc), "heap_alloc")
+	ch <- prometheus.MustNewConstMetric(c.memoryDesc, prometheus.GaugeValue, float64(ms.HeapInuse), "heap_inuse")
+	return nil
+}
+
+// 每秒执行一次，记录实际间隔与 1 秒的差值
+type tickProbe struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (p *tickProbe) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if !p.last.IsZero() {
+		lag := now.Sub(p.last) - time.Second
+		if lag < 0 {
+			lag = 0
+		}
+		tickLag.Set(lag.Seconds())
+	}
+	p.last = now
+}
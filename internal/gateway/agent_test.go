@@ -0,0 +1,43 @@
+package gateway
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPushStats(t *testing.T) {
+	s := &pushStats{output: "test-stats"}
+	s.observePush(time.Now(), 200, 100, nil)
+	s.observePush(time.Now(), 503, 100, &statusError{code: 503})
+	s.observePush(time.Now(), 0, 100, errors.New("connection refused"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(pushRequests.WithLabelValues("test-stats", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pushRequests.WithLabelValues("test-stats", "503")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pushRequests.WithLabelValues("test-stats", "error")))
+	assert.Equal(t, 100.0, testutil.ToFloat64(pushSentBytes.WithLabelValues("test-stats")))
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(pushLastSuccess.WithLabelValues("test-stats")), 2)
+
+	s.observePayload(1000, 250)
+	raw, encoded := s.payload()
+	assert.Equal(t, 1000, raw)
+	assert.Equal(t, 250, encoded)
+}
+
+func TestRuntimeCollector(t *testing.T) {
+	SetBuildInfo("v1.0.0", "abc123")
+	ch := make(chan prometheus.Metric, 10)
+	require.NoError(t, newRuntimeCollector().Update(ch))
+	close(ch)
+
+	var names []string
+	for m := range ch {
+		names = append(names, m.Desc().String())
+	}
+	require.Len(t, names, 5)
+	assert.Contains(t, names[0], "fildr_agent_build_info")
+}
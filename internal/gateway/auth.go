@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"errors"
+	"fildr-cli/internal/config"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 连续认证失败达到该次数后视为认证失效
+const authFailureThreshold = 3
+
+// 为请求添加认证信息，并记录连续认证失败的次数
+type authenticator struct {
+	output string
+	header string
+	scheme string
+	token  string
+	cfg    config.Auth
+
+	mu       sync.Mutex
+	failures int
+}
+
+// token 为输出配置中的令牌，auth 中没有配置令牌时使用
+func newAuthenticator(output string, cfg config.Auth, token, defaultHeader string) *authenticator {
+	a := &authenticator{
+		output: output,
+		header: cfg.Header,
+		scheme: cfg.Scheme,
+		token:  cfg.Token,
+		cfg:    cfg,
+	}
+	if a.header == "" {
+		a.header = defaultHeader
+	}
+	if a.scheme == "" {
+		a.scheme = "Bearer"
+	}
+	if a.token == "" {
+		a.token = token
+	}
+	return a
+}
+
+// 每次请求时重新读取令牌文件和环境变量，令牌更新后无需重启
+func (a *authenticator) getToken() (string, error) {
+	if a.cfg.TokenFile != "" {
+		b, err := ioutil.ReadFile(a.cfg.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("read token file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if a.cfg.TokenEnv != "" {
+		token := os.Getenv(a.cfg.TokenEnv)
+		if token == "" {
+			return "", fmt.Errorf("environment variable %s is empty", a.cfg.TokenEnv)
+		}
+		return token, nil
+	}
+	return a.token, nil
+}
+
+func (a *authenticator) getPassword() (string, error) {
+	if a.cfg.PasswordFile != "" {
+		b, err := ioutil.ReadFile(a.cfg.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("read password file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return a.cfg.Password, nil
+}
+
+func (a *authenticator) apply(header http.Header) error {
+	if a.cfg.Username != "" {
+		password, err := a.getPassword()
+		if err != nil {
+			return err
+		}
+		basic := base64.StdEncoding.EncodeToString([]byte(a.cfg.Username + ":" + password))
+		header.Set("Authorization", "Basic "+basic)
+	}
+
+	token, err := a.getToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return nil
+	}
+	if strings.EqualFold(a.scheme, "none") {
+		header.Set(a.header, token)
+	} else {
+		header.Set(a.header, a.scheme+" "+token)
+	}
+	return nil
+}
+
+// 根据推送结果更新连续认证失败次数，达到阈值和恢复时各输出一次日志
+func (a *authenticator) observe(err error) {
+	var se *statusError
+	failed := errors.As(err, &se) && (se.code == http.StatusUnauthorized || se.code == http.StatusForbidden)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if failed {
+		a.failures++
+		if a.failures == authFailureThreshold {
+			logger.Errorf("output %s authentication failed %d times in a row, check the token or credentials", a.output, a.failures)
+		} else {
+			logger.Warnf("output %s unauthorized: %v", a.output, err)
+		}
+		return
+	}
+	if err == nil {
+		if a.failures >= authFailureThreshold {
+			logger.Infof("output %s authentication recovered", a.output)
+		}
+		a.failures = 0
+	}
+}
+
+func (a *authenticator) consecutiveFailures() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.failures
+}
+
+// 配置了 TLS 时为输出创建独立的 http 客户端，否则使用共享的客户端
+func newHttpClient(cfg config.TLS) (*http.Client, error) {
+	if !cfg.Enabled() {
+		return getHttpClient(), nil
+	}
+	tlsConfig, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: MaxIdleConnections,
+			TLSClientConfig:     tlsConfig,
+		},
+		Timeout: time.Duration(RequestTimeout) * time.Second,
+	}, nil
+}
+
+// 供其他模块向外部服务发送数据时复用证书设置
+func NewHttpClient(cfg config.TLS) (*http.Client, error) {
+	return newHttpClient(cfg)
+}
+
+// 返回为请求添加认证信息的函数，未配置 header 时使用 defaultHeader
+func AuthHeader(name string, cfg config.Auth, defaultHeader string) (func(http.Header) error, error) {
+	if err := cfg.Validate("", defaultHeader); err != nil {
+		return nil, err
+	}
+	return newAuthenticator(name, cfg, "", defaultHeader).apply, nil
+}
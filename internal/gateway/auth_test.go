@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"errors"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticatorApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auth")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	header := http.Header{}
+	require.NoError(t, newAuthenticator("gw", config.Auth{}, "t0", "blade-auth").apply(header))
+	assert.Equal(t, "Bearer t0", header.Get("blade-auth"))
+
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("t1\n"), 0600))
+	header = http.Header{}
+	a := newAuthenticator("gw", config.Auth{Header: "X-Token", Scheme: "none", TokenFile: tokenFile}, "t0", "blade-auth")
+	require.NoError(t, a.apply(header))
+	assert.Equal(t, "t1", header.Get("X-Token"))
+	assert.Empty(t, header.Get("blade-auth"))
+
+	os.Setenv("FILDR_TEST_TOKEN", "t2")
+	defer os.Unsetenv("FILDR_TEST_TOKEN")
+	header = http.Header{}
+	require.NoError(t, newAuthenticator("rw", config.Auth{TokenEnv: "FILDR_TEST_TOKEN"}, "", "Authorization").apply(header))
+	assert.Equal(t, "Bearer t2", header.Get("Authorization"))
+
+	assert.Error(t, newAuthenticator("rw", config.Auth{TokenEnv: "FILDR_TEST_MISSING"}, "", "Authorization").apply(http.Header{}))
+
+	header = http.Header{}
+	require.NoError(t, newAuthenticator("rw", config.Auth{Username: "u", Password: "p"}, "", "X-Token").apply(header))
+	assert.Equal(t, "Basic dTpw", header.Get("Authorization"))
+}
+
+func TestAuthenticatorObserve(t *testing.T) {
+	logger = log.NopLogger()
+	a := newAuthenticator("gw", config.Auth{}, "", "blade-auth")
+
+	for i := 0; i < authFailureThreshold; i++ {
+		a.observe(&statusError{code: http.StatusUnauthorized})
+	}
+	assert.Equal(t, authFailureThreshold, a.consecutiveFailures())
+
+	// 网络错误不影响认证状态
+	a.observe(errors.New("connection refused"))
+	assert.Equal(t, authFailureThreshold, a.consecutiveFailures())
+
+	a.observe(nil)
+	assert.Equal(t, 0, a.consecutiveFailures())
+}
+
+func TestAuthBasicWithToken(t *testing.T) {
+	// basic 认证和令牌都使用 Authorization 时令牌会覆盖 basic 认证
+	basic := config.Auth{Username: "u", Password: "p"}
+	assert.NoError(t, basic.Validate("", "Authorization"))
+	assert.Error(t, basic.Validate("t0", "Authorization"))
+	assert.NoError(t, basic.Validate("t0", "blade-auth"))
+
+	withToken := basic
+	withToken.TokenEnv = "FILDR_TEST_TOKEN"
+	assert.Error(t, withToken.Validate("", "Authorization"))
+	withToken.Header = "X-Token"
+	assert.NoError(t, withToken.Validate("", "Authorization"))
+
+	// remote_write 默认使用 Authorization，输出的 token 同样冲突
+	_, err := newOutput(config.Output{Name: "rw", Type: config.OutputRemoteWrite, Url: "http://127.0.0.1:1", Token: "t0", Auth: basic}, config.Gateway{}, "")
+	assert.Error(t, err)
+	_, err = newOutput(config.Output{Name: "pg", Url: "http://127.0.0.1:1", Token: "t0", Auth: basic}, config.Gateway{}, "")
+	assert.NoError(t, err)
+
+	withToken.Header = "authorization"
+	_, err = AuthHeader("logs", withToken, "X-Token")
+	assert.Error(t, err)
+}
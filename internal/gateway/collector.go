@@ -1,13 +1,11 @@
 package gateway
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fildr-cli/internal/config"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/common/expfmt"
 	"sync"
 	"time"
 )
@@ -16,6 +14,9 @@ type Collector interface {
 	Update(ch chan<- prometheus.Metric) error
 }
 
+// agent 自身指标的命名空间
+const agentNamespace = "fildr_agent"
+
 var registries = make(map[string]*prometheus.Registry)
 var pcs = make(map[string]*promCollector)
 
@@ -107,24 +108,15 @@ func (p *promCollector) Collect(ch chan<- prometheus.Metric) {
 	wg.Wait()
 }
 
-func getMetrics() ([]*MetricData, error) {
-	datas := make([]*MetricData, 0)
-	for k, v := range registries {
-		mfs, err := v.Gather()
-		if err != nil {
-			return nil, err
-		}
-		buf := &bytes.Buffer{}
-		enc := expfmt.NewEncoder(buf, expfmt.FmtText)
-		for _, mf := range mfs {
-			if err := enc.Encode(mf); err != nil {
-				return nil, err
-			}
-		}
-		pc := pcs[k]
-		datas = append(datas, &MetricData{instance: pc.instance, job: pc.job, data: buf})
+// 收集命名空间下的全部指标
+func getMetrics(namespace string) (*MetricData, error) {
+	now := time.Now()
+	mfs, err := registries[namespace].Gather()
+	if err != nil {
+		return nil, err
 	}
-	return datas, nil
+	pc := pcs[namespace]
+	return &MetricData{instance: pc.instance, job: pc.job, timestamp: now, families: mfs}, nil
 }
 
 var ErrNoData = errors.New("collector returned no data")
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fildr-cli/internal/config"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"sync"
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdErr     error
+)
+
+func checkCompression(compression string) error {
+	switch compression {
+	case "", config.CompressionNone, config.CompressionGzip, config.CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unknown compression %q", compression)
+	}
+}
+
+// 压缩方式对应的 Content-Encoding，不压缩时为空
+func contentEncoding(compression string) string {
+	switch compression {
+	case config.CompressionGzip, config.CompressionZstd:
+		return compression
+	default:
+		return ""
+	}
+}
+
+// 按配置压缩请求体
+func compress(compression string, body []byte) ([]byte, error) {
+	switch compression {
+	case config.CompressionGzip:
+		buf := &bytes.Buffer{}
+		w := gzip.NewWriter(buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case config.CompressionZstd:
+		zstdOnce.Do(func() {
+			zstdEncoder, zstdErr = zstd.NewWriter(nil)
+		})
+		if zstdErr != nil {
+			return nil, zstdErr
+		}
+		return zstdEncoder.EncodeAll(body, nil), nil
+	default:
+		return body, nil
+	}
+}
@@ -0,0 +1,45 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fildr-cli/internal/config"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestCompress(t *testing.T) {
+	buf, err := testMetricData("node", time.Now()).encode(false)
+	require.NoError(t, err)
+	raw := bytes.Repeat(buf.Bytes(), 100)
+
+	body, err := compress(config.CompressionGzip, raw)
+	require.NoError(t, err)
+	assert.Less(t, len(body), len(raw))
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	require.NoError(t, err)
+	out, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, raw, out)
+
+	body, err = compress(config.CompressionZstd, raw)
+	require.NoError(t, err)
+	assert.Less(t, len(body), len(raw))
+	d, err := zstd.NewReader(nil)
+	require.NoError(t, err)
+	out, err = d.DecodeAll(body, nil)
+	require.NoError(t, err)
+	assert.Equal(t, raw, out)
+
+	body, err = compress(config.CompressionNone, raw)
+	require.NoError(t, err)
+	assert.Equal(t, raw, body)
+	assert.Equal(t, "", contentEncoding(config.CompressionNone))
+	assert.Equal(t, "gzip", contentEncoding(config.CompressionGzip))
+
+	assert.Error(t, checkCompression("lz4"))
+}
@@ -1,7 +1,9 @@
 package gateway
 
 import (
-	"fildr-cli/internal/config"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -17,7 +19,24 @@ const (
 	RequestTimeout     int = 30
 )
 
-func postGateway(data *MetricData) {
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("push gateway returned status %d", e.code)
+}
+
+// 网络错误、服务端错误以及限流可以稍后重试
+func isRetryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError || se.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func getHttpClient() *http.Client {
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Transport: &http.Transport{
@@ -26,31 +45,27 @@ func postGateway(data *MetricData) {
 			Timeout: time.Duration(RequestTimeout) * time.Second,
 		}
 	}
+	return httpClient
+}
 
-	cfg := config.Get()
-	url := cfg.Gateway.Url
-	if url[len(url)-1] == '/' {
-		url = url + "metrics/job/" + data.job + "/instance/" + data.instance
-	} else {
-		url = url + "/metrics/job/" + data.job + "/instance/" + data.instance
-	}
-
-	req, err := http.NewRequest(http.MethodPost, url, data.data)
+// 发送 POST 请求，返回响应状态码，非 2xx 响应返回 statusError，网络错误时状态码为 0
+func post(client *http.Client, url string, header http.Header, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return
+		return 0, err
 	}
-	req.Header.Add("blade-auth", "Bearer "+cfg.Gateway.Token)
-	req.Header.Add("Content-Type", "text/plain")
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		logger.Warnf("push gateway err: %v", err)
-		return
+	for k, v := range header {
+		req.Header[k] = v
 	}
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnauthorized {
-			logger.Warnf("%d push gateway unauthorized.", resp.StatusCode)
-		}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
 	}
 	io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, &statusError{code: resp.StatusCode}
+	}
+	return resp.StatusCode, nil
 }
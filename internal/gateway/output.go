@@ -0,0 +1,273 @@
+package gateway
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fmt"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// 每个输出待推送队列的长度，队列满时新数据直接写入缓存或丢弃
+const outputQueueSize = 16
+
+// 一个输出目标，有独立的推送队列和缓存，推送慢或失败不影响其他输出
+type output struct {
+	cfg     config.Output
+	gateway config.Gateway
+	name    string
+	sink    Sink
+	auth    *authenticator
+	stats   *pushStats
+	filter  *metricFilter
+	spool   *spool
+	queue   chan *MetricData
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newOutput(cfg config.Output, gateway config.Gateway, spoolDir string) (*output, error) {
+	var auth *authenticator
+	var client *http.Client
+	var stats *pushStats
+	if cfg.Type != config.OutputFile && cfg.Type != config.OutputStdout {
+		defaultHeader := "blade-auth"
+		if cfg.Type == config.OutputRemoteWrite {
+			defaultHeader = "Authorization"
+		}
+		if err := cfg.Auth.Validate(cfg.Token, defaultHeader); err != nil {
+			return nil, fmt.Errorf("output %s: %w", cfg.Name, err)
+		}
+		auth = newAuthenticator(cfg.Name, cfg.Auth, cfg.Token, defaultHeader)
+		c, err := newHttpClient(cfg.Auth.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", cfg.Name, err)
+		}
+		client = c
+		stats = &pushStats{output: cfg.Name}
+	}
+	sink, err := newSink(cfg, auth, client, stats)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := newMetricFilter(cfg.Include, cfg.Exclude, cfg.Match)
+	if err != nil {
+		return nil, fmt.Errorf("output %s: %w", cfg.Name, err)
+	}
+	o := &output{
+		cfg:     cfg,
+		gateway: gateway,
+		name:    cfg.Name,
+		sink:    sink,
+		auth:    auth,
+		stats:   stats,
+		filter:  filter,
+		queue:   make(chan *MetricData, outputQueueSize),
+		last:    make(map[string]time.Time),
+	}
+
+	// 只有网络输出需要缓存
+	spoolCfg := gateway.Spool
+	if spoolCfg.Enable && auth != nil {
+		dir := filepath.Join(spoolDir, cfg.Name)
+		sp, err := newSpool(dir, spoolCfg.MaxBytes, spoolCfg.MaxAge, sink)
+		if err != nil {
+			return nil, fmt.Errorf("create spool %s: %w", dir, err)
+		}
+		// pushgateway 不接受时间戳且每组指标只保存最新值，只缓存每个 job 最新的数据
+		sp.latestOnly = cfg.Type != config.OutputRemoteWrite
+		o.spool = sp
+	}
+	return o, nil
+}
+
+func (o *output) run(ctx context.Context) {
+	if o.spool != nil {
+		go o.spool.replay(ctx)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-o.queue:
+			o.push(data)
+		}
+	}
+}
+
+// 把采集到的数据放入推送队列，不会阻塞
+func (o *output) offer(data *MetricData) {
+	if !o.cfg.Accepts(data.job) {
+		return
+	}
+	if !o.due(data.job, data.timestamp) {
+		return
+	}
+	data = o.filter.apply(data)
+	if len(data.families) == 0 {
+		return
+	}
+
+	select {
+	case o.queue <- data:
+	default:
+		logger.Warnf("output %s is busy, %s metrics not pushed", o.name, data.job)
+		o.save(data)
+	}
+}
+
+func (o *output) interval(namespace string) time.Duration {
+	return o.cfg.PushInterval(o.gateway, namespace)
+}
+
+// 命名空间按所有输出中最短的间隔采集，间隔更长的输出跳过未到时间的数据
+func (o *output) due(namespace string, now time.Time) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// 时间轮精度为 1 秒，留出误差
+	if now.Sub(o.last[namespace]) < o.interval(namespace)-time.Second {
+		return false
+	}
+	o.last[namespace] = now
+	return true
+}
+
+func (o *output) push(data *MetricData) {
+	body, err := o.sink.Encode(data, false)
+	if err != nil {
+		logger.Warnf("output %s encode %s metrics err: %v", o.name, data.job, err)
+		return
+	}
+	if err := o.sink.Send(data.job, data.instance, body); err != nil {
+		logger.Warnf("output %s push err: %v", o.name, err)
+		if isRetryable(err) {
+			o.save(data)
+		}
+		return
+	}
+	if o.spool != nil {
+		// 缓存的旧数据会覆盖刚推送的数据
+		if o.spool.latestOnly {
+			o.spool.discard(data.job)
+		}
+		o.spool.notify()
+	}
+}
+
+func (o *output) save(data *MetricData) {
+	if o.spool == nil {
+		return
+	}
+	if err := o.spool.put(data); err != nil {
+		logger.Warnf("output %s spool %s metrics err: %v", o.name, data.job, err)
+	}
+}
+
+// 按指标名和标签过滤
+type metricFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	match   map[string]*regexp.Regexp
+}
+
+func newMetricFilter(include, exclude []string, match map[string]string) (*metricFilter, error) {
+	f := &metricFilter{match: make(map[string]*regexp.Regexp)}
+	for _, s := range include {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include %q: %w", s, err)
+		}
+		f.include = append(f.include, re)
+	}
+	for _, s := range exclude {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude %q: %w", s, err)
+		}
+		f.exclude = append(f.exclude, re)
+	}
+	for name, s := range match {
+		// 与 Prometheus 一致，标签匹配为完全匹配
+		re, err := regexp.Compile("^(?:" + s + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid match %s=%q: %w", name, s, err)
+		}
+		f.match[name] = re
+	}
+	return f, nil
+}
+
+// 返回过滤后的数据，不修改原数据
+func (f *metricFilter) apply(data *MetricData) *MetricData {
+	if len(f.include) == 0 && len(f.exclude) == 0 && len(f.match) == 0 {
+		return data
+	}
+	families := make([]*dto.MetricFamily, 0, len(data.families))
+	for _, mf := range data.families {
+		if !f.keepName(mf.GetName()) {
+			continue
+		}
+		if len(f.match) == 0 {
+			families = append(families, mf)
+			continue
+		}
+		metrics := make([]*dto.Metric, 0, len(mf.Metric))
+		for _, m := range mf.Metric {
+			if f.keepLabels(m.Label) {
+				metrics = append(metrics, m)
+			}
+		}
+		if len(metrics) > 0 {
+			families = append(families, &dto.MetricFamily{Name: mf.Name, Help: mf.Help, Type: mf.Type, Metric: metrics})
+		}
+	}
+	return &MetricData{
+		instance:  data.instance,
+		job:       data.job,
+		timestamp: data.timestamp,
+		families:  families,
+	}
+}
+
+func (f *metricFilter) keepName(name string) bool {
+	if len(f.include) > 0 {
+		matched := false
+		for _, re := range f.include {
+			if re.MatchString(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// 所有配置的标签都需匹配，缺少的标签按空字符串处理
+func (f *metricFilter) keepLabels(labels []*dto.LabelPair) bool {
+	for name, re := range f.match {
+		value := ""
+		for _, lp := range labels {
+			if lp.GetName() == name {
+				value = lp.GetValue()
+				break
+			}
+		}
+		if !re.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
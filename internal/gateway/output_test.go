@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/log"
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMetricFilter(t *testing.T) {
+	data := &MetricData{
+		job:       "node",
+		timestamp: time.Now(),
+		families: []*dto.MetricFamily{
+			{
+				Name: proto.String("node_cpu_seconds_total"),
+				Type: dto.MetricType_COUNTER.Enum(),
+				Metric: []*dto.Metric{
+					{Label: []*dto.LabelPair{{Name: proto.String("mode"), Value: proto.String("idle")}}, Counter: &dto.Counter{Value: proto.Float64(1)}},
+					{Label: []*dto.LabelPair{{Name: proto.String("mode"), Value: proto.String("user")}}, Counter: &dto.Counter{Value: proto.Float64(2)}},
+				},
+			},
+			{
+				Name:   proto.String("node_load1"),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(1)}}},
+			},
+			{
+				Name:   proto.String("go_goroutines"),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(1)}}},
+			},
+		},
+	}
+
+	f, err := newMetricFilter([]string{"^node_"}, []string{"load"}, nil)
+	require.NoError(t, err)
+	out := f.apply(data)
+	require.Len(t, out.families, 1)
+	assert.Equal(t, "node_cpu_seconds_total", out.families[0].GetName())
+	assert.Len(t, out.families[0].Metric, 2)
+
+	f, err = newMetricFilter(nil, nil, map[string]string{"mode": "idle|system"})
+	require.NoError(t, err)
+	out = f.apply(data)
+	require.Len(t, out.families, 1)
+	require.Len(t, out.families[0].Metric, 1)
+	assert.Equal(t, "idle", out.families[0].Metric[0].Label[0].GetValue())
+	// 原数据不变
+	assert.Len(t, data.families, 3)
+	assert.Len(t, data.families[0].Metric, 2)
+
+	_, err = newMetricFilter([]string{"("}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestOutputFanOut(t *testing.T) {
+	logger = log.NopLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+	slow := &output{
+		name:  "slow",
+		sink:  funcSink(func(job, instance string, body []byte) error { <-block; return nil }),
+		queue: make(chan *MetricData, 1),
+		last:  make(map[string]time.Time),
+	}
+	got := make(chan string, 10)
+	fast := &output{
+		name:  "fast",
+		sink:  funcSink(func(job, instance string, body []byte) error { got <- job; return nil }),
+		cfg:   config.Output{Namespaces: []string{"node"}},
+		queue: make(chan *MetricData, 1),
+		last:  make(map[string]time.Time),
+	}
+	for _, o := range []*output{slow, fast} {
+		o.filter, _ = newMetricFilter(nil, nil, nil)
+		go o.run(ctx)
+	}
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		ts := now.Add(time.Duration(i) * time.Minute)
+		for _, o := range []*output{slow, fast} {
+			o.offer(testMetricData("node", ts))
+			o.offer(testMetricData("lotus", ts))
+		}
+		select {
+		case job := <-got:
+			assert.Equal(t, "node", job)
+		case <-time.After(time.Second):
+			t.Fatal("fast output blocked by slow output")
+		}
+	}
+	assert.Empty(t, got)
+}
+
+func TestOutputSpool(t *testing.T) {
+	logger = log.NopLogger()
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	gw := config.Gateway{Spool: config.Spool{Enable: true}}
+	rw, err := newOutput(config.Output{Name: "rw", Type: config.OutputRemoteWrite, Url: "http://127.0.0.1:1"}, gw, dir)
+	require.NoError(t, err)
+	require.NotNil(t, rw.spool)
+	assert.False(t, rw.spool.latestOnly)
+
+	// 默认输出为 pushgateway
+	pg, err := newOutput(config.Output{Name: "pg", Url: "http://127.0.0.1:1"}, gw, dir)
+	require.NoError(t, err)
+	require.NotNil(t, pg.spool)
+	assert.True(t, pg.spool.latestOnly)
+
+	// pushgateway 不接受样本时间戳，缓存的数据同样不带时间戳
+	body, err := pg.sink.Encode(testMetricData("node", time.Now()), true)
+	require.NoError(t, err)
+	assert.Equal(t, "# TYPE node_up gauge\nnode_up 1\n", string(body))
+}
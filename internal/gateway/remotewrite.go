@@ -0,0 +1,170 @@
+package gateway
+
+import (
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Prometheus remote_write 协议，请求体为 snappy 压缩的 WriteRequest
+type remoteWriteSink struct {
+	url    string
+	auth   *authenticator
+	client *http.Client
+	stats  *pushStats
+}
+
+type label struct {
+	name, value string
+}
+
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+type timeSeries struct {
+	labels  []label
+	samples []sample
+}
+
+func (s *remoteWriteSink) Encode(data *MetricData, spooled bool) ([]byte, error) {
+	raw := marshalWriteRequest(toTimeSeries(data))
+	body := snappy.Encode(nil, raw)
+	if !spooled {
+		s.stats.observePayload(len(raw), len(body))
+	}
+	return body, nil
+}
+
+func (s *remoteWriteSink) Send(job, instance string, body []byte) error {
+	header := http.Header{}
+	header.Set("Content-Encoding", "snappy")
+	header.Set("Content-Type", "application/x-protobuf")
+	header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if err := s.auth.apply(header); err != nil {
+		return err
+	}
+	begin := time.Now()
+	code, err := post(s.client, s.url, header, body)
+	s.stats.observePush(begin, code, len(body), err)
+	s.auth.observe(err)
+	return err
+}
+
+// 将指标族展开为时间序列，summary 和 histogram 按 Prometheus 的规则拆分为多个序列，
+// 每个序列附加 job、instance 标签
+func toTimeSeries(data *MetricData) []timeSeries {
+	defaultTs := data.timestamp.UnixNano() / int64(time.Millisecond)
+	series := make([]timeSeries, 0)
+
+	for _, mf := range data.families {
+		name := mf.GetName()
+		for _, m := range mf.Metric {
+			ts := defaultTs
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			add := func(name string, value float64, extra ...label) {
+				labels := make([]label, 0, len(m.Label)+len(extra)+3)
+				labels = append(labels, label{model.MetricNameLabel, name})
+				for _, lp := range m.Label {
+					labels = append(labels, label{lp.GetName(), lp.GetValue()})
+				}
+				labels = append(labels, extra...)
+				labels = appendMissingLabel(labels, model.JobLabel, data.job)
+				labels = appendMissingLabel(labels, model.InstanceLabel, data.instance)
+				sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+				series = append(series, timeSeries{labels: labels, samples: []sample{{value: value, timestamp: ts}}})
+			}
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				for _, q := range m.GetSummary().Quantile {
+					add(name, q.GetValue(), label{model.QuantileLabel, formatFloat(q.GetQuantile())})
+				}
+				add(name+"_sum", m.GetSummary().GetSampleSum())
+				add(name+"_count", float64(m.GetSummary().GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				infSeen := false
+				for _, b := range m.GetHistogram().Bucket {
+					if math.IsInf(b.GetUpperBound(), +1) {
+						infSeen = true
+					}
+					add(name+"_bucket", float64(b.GetCumulativeCount()), label{model.BucketLabel, formatFloat(b.GetUpperBound())})
+				}
+				if !infSeen {
+					add(name+"_bucket", float64(m.GetHistogram().GetSampleCount()), label{model.BucketLabel, "+Inf"})
+				}
+				add(name+"_sum", m.GetHistogram().GetSampleSum())
+				add(name+"_count", float64(m.GetHistogram().GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+// 指标自带的 job、instance 标签优先
+func appendMissingLabel(labels []label, name, value string) []label {
+	for _, l := range labels {
+		if l.name == name {
+			return labels
+		}
+	}
+	return append(labels, label{name, value})
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// 按 prometheus.WriteRequest 的 protobuf 定义编码:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func marshalWriteRequest(series []timeSeries) []byte {
+	var req []byte
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.value)
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, lb)
+		}
+		for _, smp := range s.samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(smp.value))
+			sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(smp.timestamp))
+
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sb)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	return req
+}
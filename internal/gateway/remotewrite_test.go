@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestToTimeSeries(t *testing.T) {
+	ts := time.Unix(1600000000, 0)
+	data := &MetricData{
+		instance:  "miner01",
+		job:       "node",
+		timestamp: ts,
+		families: []*dto.MetricFamily{
+			{
+				Name: proto.String("node_load1"),
+				Type: dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{
+					Label: []*dto.LabelPair{{Name: proto.String("job"), Value: proto.String("textfile")}},
+					Gauge: &dto.Gauge{Value: proto.Float64(0.5)},
+				}},
+			},
+			{
+				Name: proto.String("push_seconds"),
+				Type: dto.MetricType_HISTOGRAM.Enum(),
+				Metric: []*dto.Metric{{
+					TimestampMs: proto.Int64(42),
+					Histogram: &dto.Histogram{
+						SampleCount: proto.Uint64(3),
+						SampleSum:   proto.Float64(1.5),
+						Bucket: []*dto.Bucket{
+							{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(1)},
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	series := toTimeSeries(data)
+	require.Len(t, series, 5)
+
+	assert.Equal(t, []label{
+		{"__name__", "node_load1"},
+		{"instance", "miner01"},
+		{"job", "textfile"},
+	}, series[0].labels)
+	assert.Equal(t, []sample{{value: 0.5, timestamp: 1600000000000}}, series[0].samples)
+
+	assert.Equal(t, label{"le", "0.1"}, series[1].labels[3])
+	assert.Equal(t, label{"le", "+Inf"}, series[2].labels[3])
+	assert.Equal(t, []sample{{value: 3, timestamp: 42}}, series[2].samples)
+	assert.Equal(t, "push_seconds_sum", series[3].labels[0].value)
+	assert.Equal(t, "push_seconds_count", series[4].labels[0].value)
+}
+
+func TestMarshalWriteRequest(t *testing.T) {
+	body := snappy.Encode(nil, marshalWriteRequest([]timeSeries{{
+		labels:  []label{{"__name__", "up"}},
+		samples: []sample{{value: 1, timestamp: 1000}},
+	}}))
+
+	req, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+
+	num, typ, n := protowire.ConsumeTag(req)
+	require.True(t, n > 0)
+	assert.Equal(t, protowire.Number(1), num)
+	assert.Equal(t, protowire.BytesType, typ)
+	ts, _ := protowire.ConsumeBytes(req[n:])
+
+	var labels []string
+	var samples []sample
+	for len(ts) > 0 {
+		num, _, n := protowire.ConsumeTag(ts)
+		v, m := protowire.ConsumeBytes(ts[n:])
+		ts = ts[n+m:]
+		for len(v) > 0 {
+			_, typ, n := protowire.ConsumeTag(v)
+			v = v[n:]
+			switch {
+			case num == 1:
+				s, m := protowire.ConsumeString(v)
+				labels = append(labels, s)
+				v = v[m:]
+			case typ == protowire.Fixed64Type:
+				f, m := protowire.ConsumeFixed64(v)
+				samples = append(samples, sample{value: math.Float64frombits(f)})
+				v = v[m:]
+			default:
+				i, m := protowire.ConsumeVarint(v)
+				samples[len(samples)-1].timestamp = int64(i)
+				v = v[m:]
+			}
+		}
+	}
+	assert.Equal(t, []string{"__name__", "up"}, labels)
+	assert.Equal(t, []sample{{value: 1, timestamp: 1000}}, samples)
+}
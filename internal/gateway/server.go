@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 启动本地指标抓取服务，支持 /metrics、/metrics?job=<namespace> 以及 /metrics/<namespace>
+func serve(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	opts := promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}
+	gatherers := prometheus.Gatherers{}
+	handlers := make(map[string]http.Handler)
+	for namespace, registry := range registries {
+		gatherers = append(gatherers, registry)
+		handlers[namespace] = promhttp.HandlerFor(registry, opts)
+	}
+	all := promhttp.HandlerFor(gatherers, opts)
+
+	metrics := func(w http.ResponseWriter, r *http.Request) {
+		job := strings.Trim(strings.TrimPrefix(r.URL.Path, "/metrics"), "/")
+		if job == "" {
+			job = r.URL.Query().Get("job")
+		}
+		if job == "" {
+			all.ServeHTTP(w, r)
+			return
+		}
+		handler, ok := handlers[job]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metrics)
+	mux.HandleFunc("/metrics/", metrics)
+
+	srv := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("metrics server err: %v", err)
+		}
+	}()
+
+	logger.Infof("serving metrics on %s", listener.Addr())
+	return nil
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"fildr-cli/internal/config"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 指标输出，编码与发送分开，发送失败时可缓存编码后的数据稍后重放
+type Sink interface {
+	// 编码指标，spooled 为 true 时表示写入缓存稍后重放，样本需带上采集时间
+	Encode(data *MetricData, spooled bool) ([]byte, error)
+	Send(job, instance string, body []byte) error
+}
+
+// auth、client 和 stats 只用于网络输出
+func newSink(cfg config.Output, auth *authenticator, client *http.Client, stats *pushStats) (Sink, error) {
+	if err := checkCompression(cfg.Compression); err != nil {
+		return nil, err
+	}
+	if cfg.Type != "" && cfg.Type != config.OutputPushgateway && cfg.Compression != "" && cfg.Compression != config.CompressionNone {
+		return nil, fmt.Errorf("compression is not supported by %s output", cfg.Type)
+	}
+
+	switch cfg.Type {
+	case "", config.OutputPushgateway:
+		return &pushgatewaySink{url: cfg.Url, compression: cfg.Compression, auth: auth, client: client, stats: stats}, nil
+	case config.OutputRemoteWrite:
+		return &remoteWriteSink{url: cfg.Url, auth: auth, client: client, stats: stats}, nil
+	case config.OutputFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file output requires a path")
+		}
+		return &fileSink{path: cfg.Path}, nil
+	case config.OutputStdout:
+		return &writerSink{w: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("unknown output type %q", cfg.Type)
+	}
+}
+
+// pushgateway 文本协议 POST /metrics/job/<job>/instance/<instance>
+type pushgatewaySink struct {
+	url         string
+	compression string
+	auth        *authenticator
+	client      *http.Client
+	stats       *pushStats
+}
+
+// pushgateway 不接受样本时间戳，缓存的数据同样不带时间戳，重放时作为最新值推送
+func (s *pushgatewaySink) Encode(data *MetricData, spooled bool) ([]byte, error) {
+	buf, err := data.encode(false)
+	if err != nil {
+		return nil, err
+	}
+	body, err := compress(s.compression, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	s.stats.observePayload(buf.Len(), len(body))
+	return body, nil
+}
+
+func (s *pushgatewaySink) Send(job, instance string, body []byte) error {
+	url := strings.TrimSuffix(s.url, "/") + "/metrics/job/" + job + "/instance/" + instance
+	header := http.Header{}
+	header.Set("Content-Type", "text/plain")
+	if encoding := contentEncoding(s.compression); encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	if err := s.auth.apply(header); err != nil {
+		return err
+	}
+	begin := time.Now()
+	code, err := post(s.client, url, header, body)
+	s.stats.observePush(begin, code, len(body), err)
+	s.auth.observe(err)
+	return err
+}
+
+// 以带时间戳的文本格式追加写入文件
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSink) Encode(data *MetricData, spooled bool) ([]byte, error) {
+	buf, err := data.encode(true)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *fileSink) Send(job, instance string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// 以带时间戳的文本格式写到标准输出，便于调试
+type writerSink struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+func (s *writerSink) Encode(data *MetricData, spooled bool) ([]byte, error) {
+	buf, err := data.encode(true)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *writerSink) Send(job, instance string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(body)
+	return err
+}
@@ -0,0 +1,256 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	spoolSuffix      = ".json"
+	minReplayBackoff = time.Second
+	maxReplayBackoff = 5 * time.Minute
+	// 没有收到通知时定期重放，输出间歇失败时缓存也能被推送
+	replayInterval = time.Minute
+)
+
+// 推送失败的指标缓存，每次推送保存为一个文件，文件名以采集时间开头，
+// 网关恢复后按时间顺序重新推送
+type spool struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+	sink     Sink
+	// 只保留每个 job 最新的数据，用于只保存最新值的 pushgateway
+	latestOnly bool
+
+	mu   sync.Mutex
+	wake chan struct{}
+}
+
+type spoolEntry struct {
+	Job       string    `json:"job"`
+	Instance  string    `json:"instance"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      []byte    `json:"data"`
+}
+
+func newSpool(dir string, maxBytes int64, maxAge time.Duration, sink Sink) (*spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &spool{
+		dir:      dir,
+		maxBytes: maxBytes,
+		maxAge:   maxAge,
+		sink:     sink,
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// 写入缓存，样本带上采集时间，超出容量或过期的旧数据会被丢弃
+func (s *spool) put(data *MetricData) error {
+	body, err := s.sink.Encode(data, true)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(spoolEntry{
+		Job:       data.job,
+		Instance:  data.instance,
+		Timestamp: data.timestamp,
+		Data:      body,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latestOnly {
+		if err := s.removeJob(data.job); err != nil {
+			return err
+		}
+	}
+	name := filepath.Join(s.dir, fmt.Sprintf("%020d-%s%s", data.timestamp.UnixNano(), data.job, spoolSuffix))
+	if err := ioutil.WriteFile(name+".tmp", b, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(name+".tmp", name); err != nil {
+		return err
+	}
+	return s.trim()
+}
+
+// 丢弃 job 的缓存，latestOnly 时该 job 推送成功后旧数据不再需要重放
+func (s *spool) discard(job string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.removeJob(job); err != nil {
+		logger.Warnf("remove spooled metrics err: %v", err)
+	}
+}
+
+// 删除 job 的缓存文件，调用方需持有锁
+func (s *spool) removeJob(job string) error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if spoolJob(f.Name()) != job {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// 文件名为 <20 位采集时间>-<job>.json
+func spoolJob(name string) string {
+	name = strings.TrimSuffix(name, spoolSuffix)
+	if len(name) < 21 || name[20] != '-' {
+		return ""
+	}
+	return name[21:]
+}
+
+// 通知网关已恢复，立即开始重放
+func (s *spool) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// 持续重放缓存，失败时指数退避，成功后每隔 replayInterval 检查一次
+func (s *spool) replay(ctx context.Context) {
+	backoff := minReplayBackoff
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+			backoff = minReplayBackoff
+		case <-timer.C:
+		}
+
+		timer.Stop()
+		if err := s.flush(); err != nil {
+			logger.Debugf("replay spooled metrics err: %v, retry in %s", err, backoff)
+			timer.Reset(backoff)
+			backoff *= 2
+			if backoff > maxReplayBackoff {
+				backoff = maxReplayBackoff
+			}
+			continue
+		}
+		backoff = minReplayBackoff
+		timer.Reset(replayInterval)
+	}
+}
+
+// 按时间顺序推送缓存，遇到可重试的错误时停止
+func (s *spool) flush() error {
+	for {
+		s.mu.Lock()
+		files, err := s.files()
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return nil
+		}
+
+		name := filepath.Join(s.dir, files[0].Name())
+		entry, err := readSpoolEntry(name)
+		if os.IsNotExist(err) {
+			// 已被同一 job 更新的数据替换
+			continue
+		}
+		if err != nil {
+			logger.Warnf("drop unreadable spooled metrics %s: %v", files[0].Name(), err)
+			s.remove(name)
+			continue
+		}
+		if err := s.sink.Send(entry.Job, entry.Instance, entry.Data); err != nil {
+			if isRetryable(err) {
+				return err
+			}
+			logger.Warnf("drop spooled metrics %s: %v", files[0].Name(), err)
+		}
+		s.remove(name)
+	}
+}
+
+func (s *spool) remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		logger.Warnf("remove spooled metrics err: %v", err)
+	}
+}
+
+// 缓存文件，按文件名即采集时间排序
+func (s *spool) files() ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	files := infos[:0]
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasSuffix(info.Name(), spoolSuffix) {
+			files = append(files, info)
+		}
+	}
+	return files, nil
+}
+
+// 丢弃过期以及超出容量的最旧数据，调用方需持有锁
+func (s *spool) trim() error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.Size()
+	}
+	now := time.Now()
+	for _, f := range files {
+		expired := s.maxAge > 0 && now.Sub(f.ModTime()) > s.maxAge
+		if !expired && (s.maxBytes <= 0 || total <= s.maxBytes) {
+			break
+		}
+		if err := os.Remove(filepath.Join(s.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= f.Size()
+		logger.Warnf("spool limit reached, drop spooled metrics %s", f.Name())
+	}
+	return nil
+}
+
+func readSpoolEntry(name string) (*spoolEntry, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	entry := &spoolEntry{}
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
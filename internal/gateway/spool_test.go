@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"errors"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func testMetricData(job string, ts time.Time) *MetricData {
+	return &MetricData{
+		instance:  "test",
+		job:       job,
+		timestamp: ts,
+		families: []*dto.MetricFamily{{
+			Name:   proto.String(job + "_up"),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(1)}}},
+		}},
+	}
+}
+
+type funcSink func(job, instance string, body []byte) error
+
+func (f funcSink) Encode(data *MetricData, spooled bool) ([]byte, error) {
+	buf, err := data.encode(spooled)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f funcSink) Send(job, instance string, body []byte) error {
+	return f(job, instance, body)
+}
+
+func TestSpoolReplayInOrder(t *testing.T) {
+	logger = log.NopLogger()
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var posted []string
+	fail := true
+	s, err := newSpool(dir, 0, 0, funcSink(func(job, instance string, body []byte) error {
+		if fail {
+			return errors.New("connection refused")
+		}
+		posted = append(posted, job)
+		assert.Regexp(t, `_up 1 [0-9]+\n`, string(body))
+		return nil
+	}))
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, s.put(testMetricData("lotus", now.Add(time.Second))))
+	require.NoError(t, s.put(testMetricData("node", now)))
+
+	assert.Error(t, s.flush())
+	files, err := s.files()
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	fail = false
+	require.NoError(t, s.flush())
+	assert.Equal(t, []string{"node", "lotus"}, posted)
+	files, err = s.files()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpoolTrim(t *testing.T) {
+	logger = log.NopLogger()
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := newSpool(dir, 1, 0, funcSink(nil))
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, s.put(testMetricData("node", now)))
+	require.NoError(t, s.put(testMetricData("lotus", now.Add(time.Second))))
+
+	files, err := s.files()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	s.maxBytes = 1 << 20
+	require.NoError(t, s.put(testMetricData("node", now)))
+	require.NoError(t, s.put(testMetricData("lotus", now.Add(time.Second))))
+	files, err = s.files()
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestSpoolLatestOnly(t *testing.T) {
+	logger = log.NopLogger()
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var posted []string
+	s, err := newSpool(dir, 0, 0, funcSink(func(job, instance string, body []byte) error {
+		posted = append(posted, job+" "+string(body))
+		return nil
+	}))
+	require.NoError(t, err)
+	s.latestOnly = true
+
+	now := time.Now()
+	require.NoError(t, s.put(testMetricData("node", now)))
+	require.NoError(t, s.put(testMetricData("lotus", now.Add(time.Second))))
+	require.NoError(t, s.put(testMetricData("node", now.Add(2*time.Second))))
+	require.NoError(t, s.put(testMetricData("x-node", now.Add(3*time.Second))))
+	files, err := s.files()
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	// 推送成功的 job 不再重放
+	s.discard("lotus")
+	require.NoError(t, s.flush())
+	ts := (now.Add(2 * time.Second)).UnixNano() / int64(time.Millisecond)
+	require.Len(t, posted, 2)
+	assert.Equal(t, fmt.Sprintf("node # TYPE node_up gauge\nnode_up 1 %d\n", ts), posted[0])
+	assert.Regexp(t, `^x-node `, posted[1])
+}
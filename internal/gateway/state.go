@@ -1,9 +1,70 @@
 package gateway
 
-import "bytes"
+import (
+	"bytes"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"time"
+)
 
 type MetricData struct {
-	instance string
-	job      string
-	data     *bytes.Buffer
+	instance  string
+	job       string
+	timestamp time.Time
+	families  []*dto.MetricFamily
+}
+
+func (d *MetricData) Instance() string {
+	return d.instance
+}
+
+func (d *MetricData) Job() string {
+	return d.job
+}
+
+// 采集时间
+func (d *MetricData) Timestamp() time.Time {
+	return d.timestamp
+}
+
+// 采集到的指标族，同一份数据会分发给所有输出，不能修改
+func (d *MetricData) Families() []*dto.MetricFamily {
+	return d.families
+}
+
+// 编码为文本格式，withTimestamp 为 true 时为没有时间戳的样本补充采集时间
+func (d *MetricData) encode(withTimestamp bool) (*bytes.Buffer, error) {
+	buf := &bytes.Buffer{}
+	enc := expfmt.NewEncoder(buf, expfmt.FmtText)
+	ts := d.timestamp.UnixNano() / int64(time.Millisecond)
+	for _, mf := range d.families {
+		if withTimestamp {
+			mf = withTimestamps(mf, ts)
+		}
+		if err := enc.Encode(mf); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// 返回补充了时间戳的副本，不修改共享的指标数据
+func withTimestamps(mf *dto.MetricFamily, ts int64) *dto.MetricFamily {
+	metrics := make([]*dto.Metric, 0, len(mf.Metric))
+	for _, m := range mf.Metric {
+		if m.TimestampMs != nil {
+			metrics = append(metrics, m)
+			continue
+		}
+		metrics = append(metrics, &dto.Metric{
+			Label:       m.Label,
+			Gauge:       m.Gauge,
+			Counter:     m.Counter,
+			Summary:     m.Summary,
+			Untyped:     m.Untyped,
+			Histogram:   m.Histogram,
+			TimestampMs: &ts,
+		})
+	}
+	return &dto.MetricFamily{Name: mf.Name, Help: mf.Help, Type: mf.Type, Metric: metrics}
 }
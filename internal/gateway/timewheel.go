@@ -2,13 +2,18 @@ package gateway
 
 import (
 	"context"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/log"
+	"fmt"
 	"github.com/rfyiamcool/go-timewheel"
+	"math/rand"
+	"path/filepath"
 	"time"
 )
 
 var tws *timewheel.TimeWheel
 var logger log.Logger
+var outputs []*output
 
 func init() {
 	tw, err := timewheel.NewTimeWheel(1*time.Second, 360)
@@ -20,20 +25,97 @@ func init() {
 
 func Run(ctx context.Context) error {
 	logger = log.From(ctx)
-	tws, err := timewheel.NewTimeWheel(1*time.Second, 360)
-	if err != nil {
-		return err
+	cfg := config.Get()
+
+	outputCfgs := cfg.EffectiveOutputs()
+	spoolDir := cfg.Gateway.Spool.Dir
+	if spoolDir == "" {
+		home, err := config.Dir()
+		if err != nil {
+			return err
+		}
+		spoolDir = filepath.Join(home, "spool")
 	}
-	tws.AddCron(time.Second*5, func() {
-		datas, err := getMetrics()
+
+	names := make(map[string]bool)
+	for i, oc := range outputCfgs {
+		if oc.Name == "" {
+			oc.Name = fmt.Sprintf("%s-%d", oc.Type, i)
+		}
+		if names[oc.Name] {
+			return fmt.Errorf("duplicate output name %q", oc.Name)
+		}
+		names[oc.Name] = true
+
+		o, err := newOutput(oc, cfg.Gateway, spoolDir)
 		if err != nil {
-			return
+			return err
 		}
-		for i := range datas {
-			postGateway(datas[i])
+		outputs = append(outputs, o)
+		go o.run(ctx)
+	}
+
+	registerAgent()
+
+	// 所有命名空间注册完成后再启动本地抓取服务
+	if cfg.Web.Enable {
+		if err := serve(ctx, cfg.Web.Address); err != nil {
+			return err
 		}
-	})
+	}
+
+	if len(outputs) == 0 {
+		logger.Infof("no outputs configured, push disabled")
+		return nil
+	}
+
+	for namespace := range registries {
+		namespace := namespace
+		interval := gatherInterval(namespace)
+		if interval == 0 {
+			continue
+		}
+		// 随机错开首次推送时间，避免大量客户端在同一时刻请求网关
+		offset := time.Duration(rand.Int63n(int64(interval)))
+		logger.Infof("push %s metrics every %s, first push in %s", namespace, interval, offset.Truncate(time.Second))
+
+		tws.Add(offset, func() {
+			push(namespace)
+			tws.AddCron(interval, func() {
+				push(namespace)
+			})
+		})
+	}
+
+	probe := &tickProbe{}
+	tws.AddCron(time.Second, probe.tick)
 
 	tws.Start()
 	return nil
 }
+
+// 命名空间的采集间隔，取接收该命名空间的输出中最短的推送间隔，没有输出接收时返回 0
+func gatherInterval(namespace string) time.Duration {
+	var interval time.Duration
+	for _, o := range outputs {
+		if !o.cfg.Accepts(namespace) {
+			continue
+		}
+		if i := o.interval(namespace); interval == 0 || i < interval {
+			interval = i
+		}
+	}
+	return interval
+}
+
+// 采集一次，分发给所有输出
+func push(namespace string) {
+	data, err := getMetrics(namespace)
+	if err != nil {
+		logger.Warnf("gather %s metrics err: %v", namespace, err)
+		return
+	}
+	for _, o := range outputs {
+		o.offer(data)
+	}
+}
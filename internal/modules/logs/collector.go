@@ -0,0 +1,201 @@
+package logs
+
+import (
+	"fildr-cli/internal/config"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const namespace = "logs"
+
+// 默认的 lotus 故障特征
+var defaultSignatures = []config.LogSignature{
+	{Name: "winning_post", Pattern: `failed to compute winning post|invalid winning post|winning post was invalid`},
+	{Name: "mining", Pattern: `mining block failed|failed to submit newly mined block|failed to get best mining candidate`},
+	{Name: "window_post", Pattern: `runPost failed|submitPost failed|Submitting window post .* failed`},
+	{Name: "faulty_sectors", Pattern: `DETECTED FAULTY SECTORS`},
+	{Name: "sealing", Pattern: `(?i)sealing failed|seal pre ?commit.* failed|computing seal proof failed`},
+	{Name: "chain_sync", Pattern: `failed to validate tipset|failed to sync our own block`},
+	{Name: "panic", Pattern: `^panic: `},
+}
+
+type signature struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func compileSignatures(cfg config.Logs) ([]signature, error) {
+	var list []config.LogSignature
+	if cfg.DefaultSignatures {
+		list = append(list, defaultSignatures...)
+	}
+	list = append(list, cfg.Signatures...)
+
+	var sigs []signature
+	for _, s := range list {
+		if s.Name == "" {
+			return nil, fmt.Errorf("log signature %q requires name", s.Pattern)
+		}
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log signature %s: %w", s.Name, err)
+		}
+		sigs = append(sigs, signature{name: s.Name, re: re})
+	}
+	return sigs, nil
+}
+
+type levelKey struct {
+	source, level string
+}
+
+type loggerKey struct {
+	source, logger string
+}
+
+type signatureKey struct {
+	source, signature string
+}
+
+// 日志统计，由各来源的读取协程更新，采集时输出
+type logsCollector struct {
+	lines        *prometheus.Desc
+	errors       *prometheus.Desc
+	matches      *prometheus.Desc
+	lastMatch    *prometheus.Desc
+	sourceUp     *prometheus.Desc
+	shipLines    *prometheus.Desc
+	shipDropped  *prometheus.Desc
+	shipFailures *prometheus.Desc
+	shipBuffered *prometheus.Desc
+
+	sources    map[string]tailer
+	signatures []signature
+	shipper    *shipper
+
+	mu      sync.Mutex
+	levels  map[levelKey]uint64
+	loggers map[loggerKey]uint64
+	hits    map[signatureKey]uint64
+	lastHit map[signatureKey]time.Time
+}
+
+func newLogsCollector(sources map[string]tailer, signatures []signature, shipper *shipper) *logsCollector {
+	return &logsCollector{
+		lines: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "lines_total"),
+			"Log lines read by level, unknown for lines that could not be parsed.",
+			[]string{"source", "level"}, nil,
+		),
+		errors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "errors_total"),
+			"Error and more severe log lines by logger.",
+			[]string{"source", "logger"}, nil,
+		),
+		matches: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "signature_matches_total"),
+			"Log lines matching a known failure signature.",
+			[]string{"source", "signature"}, nil,
+		),
+		lastMatch: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "signature_last_match_timestamp_seconds"),
+			"Time a failure signature was last seen.",
+			[]string{"source", "signature"}, nil,
+		),
+		sourceUp: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "source_up"),
+			"Whether the log file is open or journalctl is running.",
+			[]string{"source"}, nil,
+		),
+		shipLines: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ship", "lines_total"),
+			"Log lines shipped to the configured endpoint.",
+			nil, nil,
+		),
+		shipDropped: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ship", "dropped_total"),
+			"Log lines dropped because the ship buffer was full.",
+			nil, nil,
+		),
+		shipFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ship", "failures_total"),
+			"Failed requests to the ship endpoint.",
+			nil, nil,
+		),
+		shipBuffered: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ship", "buffered_lines"),
+			"Log lines waiting to be shipped.",
+			nil, nil,
+		),
+		sources:    sources,
+		signatures: signatures,
+		shipper:    shipper,
+		levels:     make(map[levelKey]uint64),
+		loggers:    make(map[loggerKey]uint64),
+		hits:       make(map[signatureKey]uint64),
+		lastHit:    make(map[signatureKey]time.Time),
+	}
+}
+
+// 统计一行日志
+func (c *logsCollector) observe(source, line string, e entry, ok bool, now time.Time) {
+	level := levelUnknown
+	if ok {
+		level = e.level
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.levels[levelKey{source: source, level: level}]++
+	if ok && isError(e.level) {
+		c.loggers[loggerKey{source: source, logger: e.logger}]++
+	}
+	for _, s := range c.signatures {
+		if s.re.MatchString(line) {
+			k := signatureKey{source: source, signature: s.name}
+			c.hits[k]++
+			c.lastHit[k] = now
+		}
+	}
+}
+
+func (c *logsCollector) Update(ch chan<- prometheus.Metric) error {
+	for name, t := range c.sources {
+		up := 0.0
+		if t.up() {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.sourceUp, prometheus.GaugeValue, up, name)
+	}
+
+	c.mu.Lock()
+	for k, v := range c.levels {
+		ch <- prometheus.MustNewConstMetric(c.lines, prometheus.CounterValue, float64(v), k.source, k.level)
+	}
+	for k, v := range c.loggers {
+		ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(v), k.source, k.logger)
+	}
+	// 没有匹配的特征同样输出 0，便于按增量告警
+	for name := range c.sources {
+		for _, s := range c.signatures {
+			k := signatureKey{source: name, signature: s.name}
+			ch <- prometheus.MustNewConstMetric(c.matches, prometheus.CounterValue, float64(c.hits[k]), name, s.name)
+			if t, ok := c.lastHit[k]; ok {
+				ch <- prometheus.MustNewConstMetric(c.lastMatch, prometheus.GaugeValue, float64(t.Unix()), name, s.name)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if c.shipper != nil {
+		st := c.shipper.stats()
+		ch <- prometheus.MustNewConstMetric(c.shipLines, prometheus.CounterValue, float64(st.shipped))
+		ch <- prometheus.MustNewConstMetric(c.shipDropped, prometheus.CounterValue, float64(st.dropped))
+		ch <- prometheus.MustNewConstMetric(c.shipFailures, prometheus.CounterValue, float64(st.failures))
+		ch <- prometheus.MustNewConstMetric(c.shipBuffered, prometheus.GaugeValue, float64(st.buffered))
+	}
+	return nil
+}
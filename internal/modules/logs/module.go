@@ -0,0 +1,115 @@
+package logs
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fildr-cli/internal/module"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ module.Module = (*LogsModule)(nil)
+
+// 读取 lotus daemon、miner、worker 的日志文件或 journald 单元，按级别、logger 和故障特征统计
+type LogsModule struct {
+	logger log.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(ctx context.Context) (*LogsModule, error) {
+	logger := log.From(ctx)
+	return &LogsModule{logger: logger}, nil
+}
+
+func (mod *LogsModule) Name() string {
+	return "logs"
+}
+
+func (mod *LogsModule) Start() error {
+	cfg := config.Get()
+	if !cfg.Logs.Enable {
+		return nil
+	}
+	if len(cfg.Logs.Sources) == 0 {
+		mod.logger.Warnf("logs is enabled without sources, logs is disabled")
+		return nil
+	}
+
+	sources := make(map[string]tailer)
+	for _, s := range cfg.Logs.Sources {
+		if s.Name == "" {
+			return fmt.Errorf("logs sources require name")
+		}
+		if _, ok := sources[s.Name]; ok {
+			return fmt.Errorf("duplicate logs source name %s", s.Name)
+		}
+		switch {
+		case s.Path != "" && s.Unit != "":
+			return fmt.Errorf("logs source %s: path and unit are exclusive", s.Name)
+		case s.Path != "":
+			sources[s.Name] = &fileTailer{path: s.Path, logger: mod.logger}
+		case s.Unit != "":
+			sources[s.Name] = &journalTailer{unit: s.Unit, logger: mod.logger}
+		default:
+			return fmt.Errorf("logs source %s requires path or unit", s.Name)
+		}
+	}
+
+	signatures, err := compileSignatures(cfg.Logs)
+	if err != nil {
+		return err
+	}
+
+	var ship *shipper
+	if cfg.Logs.Ship.Url != "" {
+		if ship, err = newShipper(mod.logger, cfg.Logs.Ship, cfg.Gateway.Instance); err != nil {
+			return err
+		}
+	}
+
+	collector := newLogsCollector(sources, signatures, ship)
+	gateway.Registry(namespace, "logs", collector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mod.cancel = cancel
+	for name, t := range sources {
+		name, t := name, t
+		mod.wg.Add(1)
+		go func() {
+			defer mod.wg.Done()
+			t.run(ctx, func(line string) {
+				now := time.Now()
+				e, ok := parseLine(line)
+				collector.observe(name, line, e, ok, now)
+				if ship != nil {
+					level := levelUnknown
+					if ok {
+						level = e.level
+					}
+					ship.offer(name, level, line, now)
+				}
+			})
+		}()
+	}
+	if ship != nil {
+		mod.wg.Add(1)
+		go func() {
+			defer mod.wg.Done()
+			ship.run(ctx)
+		}()
+	}
+
+	mod.logger.Infof("logs started, %d sources, %d signatures, ship: %v", len(sources), len(signatures), ship != nil)
+	return nil
+}
+
+func (mod *LogsModule) Stop() {
+	if mod.cancel != nil {
+		mod.cancel()
+		mod.wg.Wait()
+	}
+}
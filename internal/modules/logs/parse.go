@@ -0,0 +1,65 @@
+package logs
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const levelUnknown = "unknown"
+
+// 解析后的日志行
+type entry struct {
+	level  string
+	logger string
+	msg    string
+}
+
+var knownLevels = map[string]bool{
+	"debug":  true,
+	"info":   true,
+	"warn":   true,
+	"error":  true,
+	"dpanic": true,
+	"panic":  true,
+	"fatal":  true,
+}
+
+// 计入错误数的级别
+func isError(level string) bool {
+	return level == "error" || level == "dpanic" || level == "panic" || level == "fatal"
+}
+
+// 终端输出时级别带有颜色
+var ansiColor = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// lotus 使用 go-log 输出日志，GOLOG_LOG_FMT=json 时每行为 JSON，
+// 否则为制表符分隔的 时间、级别、logger、代码位置、消息。无法解析的行（如 panic 的调用栈）返回 false
+func parseLine(line string) (entry, bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		var v struct {
+			Level  string `json:"level"`
+			Logger string `json:"logger"`
+			Msg    string `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &v); err == nil && knownLevels[strings.ToLower(v.Level)] {
+			return entry{level: strings.ToLower(v.Level), logger: v.Logger, msg: v.Msg}, true
+		}
+		return entry{}, false
+	}
+
+	fields := strings.SplitN(ansiColor.ReplaceAllString(line, ""), "\t", 5)
+	if len(fields) < 4 {
+		return entry{}, false
+	}
+	level := strings.ToLower(strings.TrimSpace(fields[1]))
+	if !knownLevels[level] {
+		return entry{}, false
+	}
+	e := entry{level: level, logger: strings.TrimSpace(fields[2])}
+	if len(fields) == 5 {
+		e.msg = fields[4]
+	}
+	return e, true
+}
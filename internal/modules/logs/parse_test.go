@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"fildr-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	e, ok := parseLine(`{"level":"error","ts":"2020-07-20T10:00:00.000+0800","logger":"miner","caller":"miner/miner.go:170","msg":"mining block failed: oops"}`)
+	assert.True(t, ok)
+	assert.Equal(t, entry{level: "error", logger: "miner", msg: "mining block failed: oops"}, e)
+
+	e, ok = parseLine("2020-07-20T10:00:00.000+0800\tWARN\tstorageminer\tstorage/wdpost_run.go:42\tchecking sector faults\t{\"count\": 1}")
+	assert.True(t, ok)
+	assert.Equal(t, "warn", e.level)
+	assert.Equal(t, "storageminer", e.logger)
+
+	// 终端输出的颜色
+	e, ok = parseLine("2020-07-20T10:00:00.000+0800\t\x1b[31mERROR\x1b[0m\tchain\tchain/sync.go:100\tfailed to validate tipset")
+	assert.True(t, ok)
+	assert.Equal(t, "error", e.level)
+
+	for _, line := range []string{"", "goroutine 1 [running]:", `{"msg":"no level"}`, "a\tb\tc"} {
+		_, ok := parseLine(line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	sigs, err := compileSignatures(config.Logs{
+		DefaultSignatures: true,
+		Signatures:        []config.LogSignature{{Name: "custom", Pattern: "disk full"}},
+	})
+	assert.NoError(t, err)
+
+	c := newLogsCollector(map[string]tailer{"miner": &fileTailer{}}, sigs, nil)
+	now := time.Now()
+	for _, line := range []string{
+		"2020-07-20T10:00:00.000+0800\tERROR\tminer\tminer/miner.go:170\tmining block failed: disk full",
+		"2020-07-20T10:00:00.000+0800\tINFO\tminer\tminer/miner.go:170\tmined new block",
+		"panic: runtime error",
+	} {
+		e, ok := parseLine(line)
+		c.observe("miner", line, e, ok, now)
+	}
+	assert.Equal(t, uint64(1), c.levels[levelKey{"miner", "error"}])
+	assert.Equal(t, uint64(1), c.levels[levelKey{"miner", "info"}])
+	assert.Equal(t, uint64(1), c.levels[levelKey{"miner", levelUnknown}])
+	assert.Equal(t, uint64(1), c.loggers[loggerKey{"miner", "miner"}])
+	assert.Equal(t, uint64(1), c.hits[signatureKey{"miner", "mining"}])
+	assert.Equal(t, uint64(1), c.hits[signatureKey{"miner", "custom"}])
+	assert.Equal(t, uint64(1), c.hits[signatureKey{"miner", "panic"}])
+
+	_, err = compileSignatures(config.Logs{Signatures: []config.LogSignature{{Name: "bad", Pattern: "("}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 发送的日志行
+type shipLine struct {
+	Time     time.Time `json:"time"`
+	Instance string    `json:"instance"`
+	Source   string    `json:"source"`
+	Level    string    `json:"level"`
+	Line     string    `json:"line"`
+}
+
+type shipStats struct {
+	shipped  uint64
+	dropped  uint64
+	failures uint64
+	buffered int
+}
+
+// 缓存日志行，满一批或到达间隔时以 JSON Lines 格式 POST 到 url，失败的日志行留在缓存中下次重试
+type shipper struct {
+	cfg      config.LogShip
+	instance string
+	levels   map[string]bool
+	client   *http.Client
+	auth     func(http.Header) error
+	logger   log.Logger
+	full     chan struct{}
+
+	mu  sync.Mutex
+	buf []shipLine
+	// buf[0] 的序号，用于发送完成后移除已发送的日志
+	head uint64
+	st   shipStats
+}
+
+func newShipper(logger log.Logger, cfg config.LogShip, instance string) (*shipper, error) {
+	client, err := gateway.NewHttpClient(cfg.Auth.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid logs ship tls: %w", err)
+	}
+	auth, err := gateway.AuthHeader("logs", cfg.Auth, "Authorization")
+	if err != nil {
+		return nil, fmt.Errorf("invalid logs ship auth: %w", err)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = config.DefaultLogShipBatchSize
+	}
+	if cfg.Interval < time.Second {
+		cfg.Interval = config.DefaultLogShipInterval
+	}
+	if cfg.MaxBuffer < cfg.BatchSize {
+		cfg.MaxBuffer = cfg.BatchSize
+	}
+	s := &shipper{
+		cfg:      cfg,
+		instance: instance,
+		client:   client,
+		auth:     auth,
+		logger:   logger,
+		full:     make(chan struct{}, 1),
+	}
+	if len(cfg.Levels) > 0 {
+		s.levels = make(map[string]bool)
+		for _, level := range cfg.Levels {
+			s.levels[strings.ToLower(level)] = true
+		}
+	}
+	return s, nil
+}
+
+func (s *shipper) offer(source, level, line string, now time.Time) {
+	if s.levels != nil && !s.levels[level] {
+		return
+	}
+	s.mu.Lock()
+	s.buf = append(s.buf, shipLine{Time: now, Instance: s.instance, Source: source, Level: level, Line: line})
+	if over := len(s.buf) - s.cfg.MaxBuffer; over > 0 {
+		s.buf = s.buf[over:]
+		s.head += uint64(over)
+		s.st.dropped += uint64(over)
+	}
+	n := len(s.buf)
+	s.mu.Unlock()
+
+	if n >= s.cfg.BatchSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *shipper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// 退出前尽量发送剩余的日志
+			s.flush()
+			return
+		case <-ticker.C:
+		case <-s.full:
+		}
+		s.flush()
+	}
+}
+
+// 按批发送缓存中的日志，失败时停止本轮发送
+func (s *shipper) flush() {
+	for {
+		s.mu.Lock()
+		n := len(s.buf)
+		if n > s.cfg.BatchSize {
+			n = s.cfg.BatchSize
+		}
+		batch := append([]shipLine(nil), s.buf[:n]...)
+		start := s.head
+		s.mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := s.send(batch); err != nil {
+			s.mu.Lock()
+			s.st.failures++
+			s.mu.Unlock()
+			s.logger.Warnf("ship logs err: %v", err)
+			return
+		}
+
+		s.mu.Lock()
+		// 发送期间缓存已满时，已发送的日志可能已被丢弃
+		if sent := start + uint64(len(batch)); sent > s.head {
+			s.buf = s.buf[sent-s.head:]
+			s.head = sent
+		}
+		s.st.shipped += uint64(len(batch))
+		s.mu.Unlock()
+	}
+}
+
+func (s *shipper) send(batch []shipLine) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, l := range batch {
+		if err := enc.Encode(l); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(http.MethodPost, s.cfg.Url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if err := s.auth(req.Header); err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("logs ship endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *shipper) stats() shipStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.st
+	st.buffered = len(s.buf)
+	return st
+}
@@ -0,0 +1,62 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/log"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShipper(t *testing.T) {
+	var got []shipLine
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var l shipLine
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &l))
+			got = append(got, l)
+		}
+	}))
+	defer server.Close()
+
+	s, err := newShipper(log.NopLogger(), config.LogShip{
+		Url:       server.URL,
+		Auth:      config.Auth{Token: "secret"},
+		Levels:    []string{"error", "unknown"},
+		BatchSize: 2,
+		MaxBuffer: 3,
+	}, "host")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	s.offer("miner", "info", "skipped", now)
+	for _, line := range []string{"a", "b", "c", "d"} {
+		s.offer("miner", "error", line, now)
+	}
+	// 超出缓存的最早一行被丢弃
+	assert.Equal(t, shipStats{dropped: 1, buffered: 3}, s.stats())
+
+	// 发送失败时保留缓存
+	s.flush()
+	assert.Equal(t, shipStats{dropped: 1, failures: 1, buffered: 3}, s.stats())
+
+	fail = false
+	s.flush()
+	assert.Equal(t, shipStats{shipped: 3, dropped: 1, failures: 1}, s.stats())
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, "b", got[0].Line)
+		assert.Equal(t, "host", got[0].Instance)
+		assert.Equal(t, "miner", got[0].Source)
+	}
+}
@@ -0,0 +1,180 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fildr-cli/internal/log"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 检查日志文件新内容和轮转的间隔
+	pollInterval = time.Second
+	// journalctl 退出后重新启动的间隔
+	restartInterval = 5 * time.Second
+	// 单行日志的最大长度，超出部分丢弃
+	maxLineBytes = 1 << 20
+)
+
+// 持续读取日志来源的新行，直到 ctx 结束
+type tailer interface {
+	run(ctx context.Context, handle func(line string))
+	up() bool
+}
+
+type status struct {
+	mu sync.Mutex
+	ok bool
+}
+
+func (s *status) set(ok bool) {
+	s.mu.Lock()
+	s.ok = ok
+	s.mu.Unlock()
+}
+
+func (s *status) up() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ok
+}
+
+// 从文件末尾开始读取，文件被轮转（重新创建）或截断后从头读取新文件
+type fileTailer struct {
+	status
+	path   string
+	logger log.Logger
+}
+
+func (t *fileTailer) run(ctx context.Context, handle func(line string)) {
+	var (
+		f       *os.File
+		info    os.FileInfo
+		reader  *bufio.Reader
+		partial strings.Builder
+	)
+	// 启动时已有的日志不再统计，启动后新建的文件从头读取
+	seekEnd := true
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	for {
+		if f == nil {
+			var err error
+			if f, info, err = openLog(t.path, seekEnd); err != nil {
+				t.logger.Debugf("open log %s err: %v", t.path, err)
+			} else {
+				reader = bufio.NewReader(f)
+				partial.Reset()
+			}
+			seekEnd = false
+		}
+
+		if f != nil {
+			for {
+				s, err := reader.ReadString('\n')
+				if partial.Len()+len(s) <= maxLineBytes {
+					partial.WriteString(s)
+				}
+				if err != nil {
+					break
+				}
+				handle(strings.TrimRight(partial.String(), "\r\n"))
+				partial.Reset()
+			}
+
+			cur, err := os.Stat(t.path)
+			if err != nil || !os.SameFile(info, cur) {
+				// 已读完旧文件，下次从头读取新文件
+				f.Close()
+				f = nil
+			} else if pos, err := f.Seek(0, io.SeekCurrent); err == nil && cur.Size() < pos {
+				f.Seek(0, io.SeekStart)
+				reader.Reset(f)
+				partial.Reset()
+			}
+		}
+		t.set(f != nil)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func openLog(path string, seekEnd bool) (*os.File, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if seekEnd {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+	return f, info, nil
+}
+
+// 通过 journalctl -f 读取 journald 单元的日志，-o cat 只输出消息内容
+type journalTailer struct {
+	status
+	unit   string
+	logger log.Logger
+}
+
+func (t *journalTailer) run(ctx context.Context, handle func(line string)) {
+	for {
+		if err := t.follow(ctx, handle); err != nil && ctx.Err() == nil {
+			t.logger.Warnf("journalctl for %s exited: %v", t.unit, err)
+		}
+		t.set(false)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartInterval):
+		}
+	}
+}
+
+func (t *journalTailer) follow(ctx context.Context, handle func(line string)) error {
+	cmd := exec.CommandContext(ctx, "journalctl", "-f", "-n", "0", "-o", "cat", "-u", t.unit)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	t.set(true)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		handle(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
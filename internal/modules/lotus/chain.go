@@ -0,0 +1,72 @@
+package lotus
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/ipfs/go-cid"
+	"time"
+)
+
+// 与 types.TipSet 的 JSON 一致，ParentBaseFee 只有新版本 lotus 返回
+type chainTipSet struct {
+	Cids   []cid.Cid
+	Blocks []chainBlock
+	Height abi.ChainEpoch
+}
+
+type chainBlock struct {
+	Miner         address.Address
+	Timestamp     uint64
+	ParentBaseFee *types.BigInt
+}
+
+func (ts *chainTipSet) minTimestamp() uint64 {
+	var min uint64
+	for i, b := range ts.Blocks {
+		if i == 0 || b.Timestamp < min {
+			min = b.Timestamp
+		}
+	}
+	return min
+}
+
+func (ts *chainTipSet) key() types.TipSetKey {
+	return types.NewTipSetKey(ts.Cids...)
+}
+
+func (ts *chainTipSet) baseFee() *types.BigInt {
+	if len(ts.Blocks) == 0 {
+		return nil
+	}
+	return ts.Blocks[0].ParentBaseFee
+}
+
+// 按创世时间和出块间隔计算当前时间应到达的高度
+func expectedHeight(genesis uint64, blockDelay time.Duration, now time.Time) abi.ChainEpoch {
+	elapsed := now.Sub(time.Unix(int64(genesis), 0))
+	if elapsed < 0 || blockDelay <= 0 {
+		return 0
+	}
+	return abi.ChainEpoch(elapsed / blockDelay)
+}
+
+func syncStageString(v api.SyncStateStage) string {
+	switch v {
+	case api.StageIdle:
+		return "idle"
+	case api.StageHeaders:
+		return "header sync"
+	case api.StagePersistHeaders:
+		return "persisting headers"
+	case api.StageMessages:
+		return "message sync"
+	case api.StageSyncComplete:
+		return "complete"
+	case api.StageSyncErrored:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,143 @@
+package lotus
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"sync"
+	"time"
+)
+
+const (
+	// 连接失败后的重试间隔，每次失败翻倍
+	minDialBackoff = time.Second
+	maxDialBackoff = 5 * time.Minute
+	// 连接建立后断开时 go-jsonrpc 自动重连的间隔
+	reconnectInterval = 5 * time.Second
+)
+
+// 同一 lotus 服务的收集器共享的连接，第一次调用时才建立连接，失败后按退避时间重试
+type rpcConn struct {
+	// daemon 或 miner
+	kind string
+	// 多个服务时的名称，作为指标的 node 标签
+	name string
+	cfg  config.Endpoint
+	env  string
+
+	mu       sync.Mutex
+	out      interface{}
+	closer   jsonrpc.ClientCloser
+	failures int
+	retryAt  time.Time
+	lastErr  error
+	maddr    address.Address
+}
+
+func newRpcConn(kind, name string, cfg config.Endpoint, env string) *rpcConn {
+	return &rpcConn{kind: kind, name: name, cfg: cfg, env: env}
+}
+
+// 调用接口使用的 context，超时时间为配置的 timeout
+func (c *rpcConn) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.cfg.CallTimeout())
+}
+
+func (c *rpcConn) connect(newOut func() interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.out != nil {
+		return c.out, nil
+	}
+	now := time.Now()
+	if now.Before(c.retryAt) {
+		return nil, fmt.Errorf("lotus %s is unavailable, retry in %s: %w", c.kind, c.retryAt.Sub(now).Round(time.Second), c.lastErr)
+	}
+
+	out := newOut()
+	closer, err := dial(c.cfg, c.env, out)
+	if err != nil {
+		backoff := minDialBackoff << uint(c.failures)
+		if backoff > maxDialBackoff || backoff <= 0 {
+			backoff = maxDialBackoff
+		}
+		c.failures++
+		c.retryAt = now.Add(backoff)
+		c.lastErr = err
+		return nil, fmt.Errorf("connect lotus %s: %w", c.kind, err)
+	}
+	c.out = out
+	c.closer = closer
+	c.failures = 0
+	c.lastErr = nil
+	return out, nil
+}
+
+func (c *rpcConn) daemon() (*Client, error) {
+	out, err := c.connect(func() interface{} { return &Client{} })
+	if err != nil {
+		return nil, err
+	}
+	return out.(*Client), nil
+}
+
+func (c *rpcConn) miner() (*MinerClient, error) {
+	out, err := c.connect(func() interface{} { return &MinerClient{} })
+	if err != nil {
+		return nil, err
+	}
+	return out.(*MinerClient), nil
+}
+
+// miner 的地址，成功获取后缓存
+func (c *rpcConn) actorAddress(ctx context.Context) (address.Address, error) {
+	c.mu.Lock()
+	maddr := c.maddr
+	c.mu.Unlock()
+	if maddr != address.Undef {
+		return maddr, nil
+	}
+
+	miner, err := c.miner()
+	if err != nil {
+		return address.Undef, err
+	}
+	maddr, err = miner.ActorAddress(ctx)
+	if err != nil {
+		return address.Undef, fmt.Errorf("get miner address: %w", err)
+	}
+	c.mu.Lock()
+	c.maddr = maddr
+	c.mu.Unlock()
+	return maddr, nil
+}
+
+// 调用 Version 检查服务是否可用
+func (c *rpcConn) ping(ctx context.Context) error {
+	if c.kind == minerEndpoint {
+		miner, err := c.miner()
+		if err != nil {
+			return err
+		}
+		_, err = miner.Version(ctx)
+		return err
+	}
+	client, err := c.daemon()
+	if err != nil {
+		return err
+	}
+	_, err = client.Version(ctx)
+	return err
+}
+
+func (c *rpcConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closer != nil {
+		c.closer()
+	}
+	c.out = nil
+	c.closer = nil
+}
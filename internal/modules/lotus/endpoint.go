@@ -0,0 +1,272 @@
+package lotus
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/gorilla/websocket"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	daemonApiInfoEnv = "FULLNODE_API_INFO"
+	minerApiInfoEnv  = "MINER_API_INFO"
+)
+
+// 收集器连接的 lotus 服务，miner 为空时只连接 daemon
+type endpoint struct {
+	// 多个 daemon 或 miner 时的名称，作为指标的 node 标签
+	name   string
+	daemon *rpcConn
+	miner  *rpcConn
+}
+
+func (e endpoint) labels() prometheus.Labels {
+	if e.name == "" {
+		return nil
+	}
+	return prometheus.Labels{"node": e.name}
+}
+
+// 收集器的注册名称，多个服务时加上服务名称
+func (e endpoint) collectorName(collector string) string {
+	if e.name == "" {
+		return collector
+	}
+	return collector + "@" + e.name
+}
+
+// 配置了矿工地址时直接使用，否则从 miner 获取，没有 miner 时返回 address.Undef
+func (e endpoint) minerAddress(ctx context.Context, maddr address.Address) (address.Address, error) {
+	if maddr != address.Undef || e.miner == nil {
+		return maddr, nil
+	}
+	return e.miner.actorAddress(ctx)
+}
+
+// 建立连接后断开时由 go-jsonrpc 按间隔重连
+func dial(cfg config.Endpoint, env string, client interface{}) (jsonrpc.ClientCloser, error) {
+	info, err := resolveEndpoint(cfg, env)
+	if err != nil {
+		return nil, err
+	}
+	return jsonrpc.NewMergeClient(info.url, "Filecoin", []interface{}{client}, info.header(),
+		jsonrpc.WithReconnectInterval(reconnectInterval))
+}
+
+// 按配置生成 daemon 和 miner 服务，同一 daemon 的服务共享连接，只有 [lotus.daemon] 和 [lotus.miner] 时读取环境变量
+func endpoints(l config.Lotus) (daemons []endpoint, miners []endpoint, conns []*rpcConn, err error) {
+	if err := l.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := setupTLS(l.TLS); err != nil {
+		return nil, nil, nil, err
+	}
+	daemonEnv, minerEnv := daemonApiInfoEnv, minerApiInfoEnv
+	if l.Multiple() {
+		daemonEnv, minerEnv = "", ""
+	}
+	daemonConns := make(map[string]*rpcConn)
+	daemonConn := func(d config.Daemon) *rpcConn {
+		if c, ok := daemonConns[d.Name]; ok {
+			return c
+		}
+		c := newRpcConn(daemonEndpoint, d.Name, d.Endpoint, daemonEnv)
+		daemonConns[d.Name] = c
+		conns = append(conns, c)
+		return c
+	}
+
+	for _, d := range l.EffectiveDaemons() {
+		daemons = append(daemons, endpoint{name: d.Name, daemon: daemonConn(d)})
+	}
+	for _, m := range l.EffectiveMiners() {
+		d, err := l.MinerDaemon(m)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		miner := newRpcConn(minerEndpoint, m.Name, m.Endpoint, minerEnv)
+		conns = append(conns, miner)
+		miners = append(miners, endpoint{name: m.Name, daemon: daemonConn(d), miner: miner})
+	}
+	return daemons, miners, conns, nil
+}
+
+// 解析后的 lotus 接口地址和令牌
+type apiInfo struct {
+	url   string
+	token string
+}
+
+func (a apiInfo) header() http.Header {
+	header := http.Header{}
+	header.Add("Content-Type", "application/json")
+	if a.token != "" {
+		header.Add("Authorization", "Bearer "+a.token)
+	}
+	return header
+}
+
+// 按 api-info、环境变量、repo 目录、ip 和 port 的顺序获取接口地址
+func resolveEndpoint(cfg config.Endpoint, env string) (apiInfo, error) {
+	version := cfg.ApiVersion
+	if version == "" {
+		version = "v0"
+	}
+	if version != "v0" && version != "v1" {
+		return apiInfo{}, fmt.Errorf("unsupported lotus api version %s", version)
+	}
+
+	var info apiInfo
+	var err error
+	switch {
+	case cfg.ApiInfo != "":
+		info, err = parseApiInfo(cfg.ApiInfo, version)
+	case env != "" && os.Getenv(env) != "":
+		info, err = parseApiInfo(os.Getenv(env), version)
+	case cfg.Repo != "":
+		info, err = readRepo(cfg.Repo, version)
+	default:
+		info.url = "ws://" + net.JoinHostPort(cfg.Ip, strconv.Itoa(cfg.Port)) + "/rpc/" + version
+	}
+	if err != nil {
+		return apiInfo{}, err
+	}
+
+	if info.token == "" {
+		if info.token, err = configToken(cfg); err != nil {
+			return apiInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+func configToken(cfg config.Endpoint) (string, error) {
+	if cfg.TokenFile != "" {
+		b, err := ioutil.ReadFile(expandHome(cfg.TokenFile))
+		if err != nil {
+			return "", fmt.Errorf("read lotus token file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	return cfg.Token, nil
+}
+
+// token:multiaddr 格式，令牌可以省略，地址也可以是 ws:// 或 wss:// 开头的 url
+func parseApiInfo(s string, version string) (apiInfo, error) {
+	s = strings.TrimSpace(s)
+	var info apiInfo
+	if !strings.HasPrefix(s, "/") && !isWsUrl(s) {
+		sp := strings.SplitN(s, ":", 2)
+		if len(sp) != 2 {
+			return apiInfo{}, fmt.Errorf("invalid lotus api info %q", s)
+		}
+		info.token = sp[0]
+		s = sp[1]
+	}
+	if isWsUrl(s) {
+		info.url = s
+		return info, nil
+	}
+	url, err := multiaddrUrl(s, version)
+	if err != nil {
+		return apiInfo{}, err
+	}
+	info.url = url
+	return info, nil
+}
+
+func isWsUrl(s string) bool {
+	return strings.HasPrefix(s, "ws://") || strings.HasPrefix(s, "wss://")
+}
+
+// 将 /ip4/127.0.0.1/tcp/1234/http 形式的地址转换为 websocket 地址
+func multiaddrUrl(s string, version string) (string, error) {
+	addr, err := ma.NewMultiaddr(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid lotus api multiaddr %q: %w", s, err)
+	}
+	var host, port string
+	scheme := "ws"
+	ma.ForEach(addr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_IP4, ma.P_IP6, ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+			host = c.Value()
+		case ma.P_TCP:
+			port = c.Value()
+		case ma.P_WSS, ma.P_HTTPS:
+			scheme = "wss"
+		}
+		return true
+	})
+	if host == "" || port == "" {
+		return "", fmt.Errorf("lotus api multiaddr %q has no host or tcp port", s)
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + "/rpc/" + version, nil
+}
+
+// 读取 lotus repo 目录下的 api 和 token 文件
+func readRepo(repo string, version string) (apiInfo, error) {
+	repo = expandHome(repo)
+	b, err := ioutil.ReadFile(filepath.Join(repo, "api"))
+	if err != nil {
+		return apiInfo{}, fmt.Errorf("read lotus api file: %w", err)
+	}
+	url, err := multiaddrUrl(strings.TrimSpace(string(b)), version)
+	if err != nil {
+		return apiInfo{}, err
+	}
+	info := apiInfo{url: url}
+	if b, err := ioutil.ReadFile(filepath.Join(repo, "token")); err == nil {
+		info.token = strings.TrimSpace(string(b))
+	} else if !os.IsNotExist(err) {
+		return apiInfo{}, fmt.Errorf("read lotus token file: %w", err)
+	}
+	return info, nil
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+var tlsSetup struct {
+	once sync.Once
+	err  error
+}
+
+// go-jsonrpc 只能使用 websocket.DefaultDialer 建立连接，证书设置对所有 lotus 连接生效。
+// 只在生成服务时设置一次，之后的连接并发建立时不再修改全局变量，未配置证书时不修改
+func setupTLS(cfg config.TLS) error {
+	tlsSetup.once.Do(func() {
+		tlsConfig, err := cfg.ClientConfig()
+		if err != nil || tlsConfig == nil {
+			tlsSetup.err = err
+			return
+		}
+		websocket.DefaultDialer = &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 45 * time.Second,
+			TLSClientConfig:  tlsConfig,
+		}
+	})
+	return tlsSetup.err
+}
@@ -0,0 +1,185 @@
+package lotus
+
+import (
+	"fildr-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiaddrUrl(t *testing.T) {
+	cases := []struct {
+		addr    string
+		version string
+		url     string
+		err     bool
+	}{
+		{"/ip4/127.0.0.1/tcp/1234/http", "v0", "ws://127.0.0.1:1234/rpc/v0", false},
+		{"/ip4/10.0.0.2/tcp/2345/http", "v1", "ws://10.0.0.2:2345/rpc/v1", false},
+		{"/ip6/::1/tcp/1234/http", "v0", "ws://[::1]:1234/rpc/v0", false},
+		{"/dns4/lotus.example.com/tcp/443/wss", "v0", "wss://lotus.example.com:443/rpc/v0", false},
+		{"/dns/lotus.example.com/tcp/443/https", "v1", "wss://lotus.example.com:443/rpc/v1", false},
+		{"/ip4/127.0.0.1/udp/1234", "v0", "", true},
+		{"/tcp/1234", "v0", "", true},
+		{"not-a-multiaddr", "v0", "", true},
+	}
+	for _, c := range cases {
+		url, err := multiaddrUrl(c.addr, c.version)
+		if c.err {
+			assert.Error(t, err, c.addr)
+			continue
+		}
+		assert.NoError(t, err, c.addr)
+		assert.Equal(t, c.url, url, c.addr)
+	}
+}
+
+func TestParseApiInfo(t *testing.T) {
+	cases := []struct {
+		info  string
+		url   string
+		token string
+		err   bool
+	}{
+		{"tk:/ip4/127.0.0.1/tcp/1234/http", "ws://127.0.0.1:1234/rpc/v0", "tk", false},
+		{"/ip4/127.0.0.1/tcp/1234/http", "ws://127.0.0.1:1234/rpc/v0", "", false},
+		{" tk:/ip4/127.0.0.1/tcp/1234/http\n", "ws://127.0.0.1:1234/rpc/v0", "tk", false},
+		{"tk:wss://lotus.example.com/rpc/v0", "wss://lotus.example.com/rpc/v0", "tk", false},
+		{"ws://127.0.0.1:1234/rpc/v0", "ws://127.0.0.1:1234/rpc/v0", "", false},
+		{"tk", "", "", true},
+		{"tk:/ip4/127.0.0.1/udp/1234", "", "", true},
+	}
+	for _, c := range cases {
+		info, err := parseApiInfo(c.info, "v0")
+		if c.err {
+			assert.Error(t, err, c.info)
+			continue
+		}
+		assert.NoError(t, err, c.info)
+		assert.Equal(t, c.url, info.url, c.info)
+		assert.Equal(t, c.token, info.token, c.info)
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lotus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo := filepath.Join(dir, "repo")
+	require.NoError(t, os.Mkdir(repo, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, "api"), []byte("/ip4/10.0.0.3/tcp/1234/http\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, "token"), []byte("repo-token\n"), 0600))
+	// 没有 token 文件的 repo
+	bare := filepath.Join(dir, "bare")
+	require.NoError(t, os.Mkdir(bare, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bare, "api"), []byte("/ip4/10.0.0.4/tcp/1234/http"), 0600))
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	const env = "FILDR_TEST_API_INFO"
+	os.Setenv(env, "env-token:/ip4/10.0.0.2/tcp/1234/http")
+	defer os.Unsetenv(env)
+
+	cases := []struct {
+		name  string
+		cfg   config.Endpoint
+		env   string
+		url   string
+		token string
+		err   bool
+	}{
+		{
+			name:  "api-info before env and repo",
+			cfg:   config.Endpoint{ApiInfo: "info-token:/ip4/10.0.0.1/tcp/1234/http", Repo: repo, Ip: "127.0.0.1", Port: 1234},
+			env:   env,
+			url:   "ws://10.0.0.1:1234/rpc/v0",
+			token: "info-token",
+		},
+		{
+			name:  "env before repo",
+			cfg:   config.Endpoint{Repo: repo, Ip: "127.0.0.1", Port: 1234},
+			env:   env,
+			url:   "ws://10.0.0.2:1234/rpc/v0",
+			token: "env-token",
+		},
+		{
+			name:  "repo before ip and port",
+			cfg:   config.Endpoint{Repo: repo, Ip: "127.0.0.1", Port: 1234},
+			url:   "ws://10.0.0.3:1234/rpc/v0",
+			token: "repo-token",
+		},
+		{
+			name: "ip and port",
+			cfg:  config.Endpoint{Ip: "127.0.0.1", Port: 1234},
+			url:  "ws://127.0.0.1:1234/rpc/v0",
+		},
+		{
+			name: "unset env is skipped",
+			cfg:  config.Endpoint{Ip: "127.0.0.1", Port: 1234},
+			env:  "FILDR_TEST_MISSING",
+			url:  "ws://127.0.0.1:1234/rpc/v0",
+		},
+		{
+			name:  "v1 path",
+			cfg:   config.Endpoint{Ip: "127.0.0.1", Port: 1234, ApiVersion: "v1", Token: "cfg-token"},
+			url:   "ws://127.0.0.1:1234/rpc/v1",
+			token: "cfg-token",
+		},
+		{
+			name:  "token file before token",
+			cfg:   config.Endpoint{Ip: "127.0.0.1", Port: 1234, Token: "cfg-token", TokenFile: tokenFile},
+			url:   "ws://127.0.0.1:1234/rpc/v0",
+			token: "file-token",
+		},
+		{
+			name:  "api-info without token uses configured token",
+			cfg:   config.Endpoint{ApiInfo: "/ip4/10.0.0.1/tcp/1234/http", Token: "cfg-token"},
+			url:   "ws://10.0.0.1:1234/rpc/v0",
+			token: "cfg-token",
+		},
+		{
+			name:  "repo without token file uses configured token",
+			cfg:   config.Endpoint{Repo: bare, Token: "cfg-token"},
+			url:   "ws://10.0.0.4:1234/rpc/v0",
+			token: "cfg-token",
+		},
+		{
+			name: "unsupported api version",
+			cfg:  config.Endpoint{Ip: "127.0.0.1", Port: 1234, ApiVersion: "v2"},
+			err:  true,
+		},
+		{
+			name: "missing repo",
+			cfg:  config.Endpoint{Repo: filepath.Join(dir, "missing")},
+			err:  true,
+		},
+		{
+			name: "missing token file",
+			cfg:  config.Endpoint{Ip: "127.0.0.1", Port: 1234, TokenFile: filepath.Join(dir, "missing")},
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		info, err := resolveEndpoint(c.cfg, c.env)
+		if c.err {
+			assert.Error(t, err, c.name)
+			continue
+		}
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.url, info.url, c.name)
+		assert.Equal(t, c.token, info.token, c.name)
+	}
+}
+
+func TestApiInfoHeader(t *testing.T) {
+	header := apiInfo{url: "ws://127.0.0.1:1234/rpc/v0", token: "tk"}.header()
+	assert.Equal(t, "Bearer tk", header.Get("Authorization"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+	header = apiInfo{url: "ws://127.0.0.1:1234/rpc/v0"}.header()
+	assert.Empty(t, header.Get("Authorization"))
+}
@@ -0,0 +1,294 @@
+package lotus
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	"github.com/ipfs/go-cid"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+// 与 api.MiningBaseInfo 的 JSON 一致，EligibleForMining 只有新版本 lotus 返回
+type miningBaseInfo struct {
+	MinerPower        types.BigInt
+	NetworkPower      types.BigInt
+	EligibleForMining *bool
+}
+
+// reward actor 状态，lotus v0.4.1 为 LastPerEpochReward，新版本 lotus 为 ThisEpochReward
+type rewardActorState struct {
+	State struct {
+		LastPerEpochReward *types.BigInt
+		ThisEpochReward    *types.BigInt
+	}
+}
+
+// 单个区块的奖励，不包含手续费
+func (s *rewardActorState) blockReward() float64 {
+	reward := s.State.ThisEpochReward
+	if reward == nil {
+		reward = s.State.LastPerEpochReward
+	}
+	if reward == nil {
+		return 0
+	}
+	return bigToFloat(*reward) / float64(builtin.ExpectedLeadersPerEpoch)
+}
+
+// 每次最多扫描的高度，避免长时间未采集后一次扫描过多
+const maxScanEpochs = 120
+
+type lotusBlocksCollector struct {
+	eligible    *prometheus.Desc
+	expectedDay *prometheus.Desc
+	expected    *prometheus.Desc
+	won         *prometheus.Desc
+	orphaned    *prometheus.Desc
+	missed      *prometheus.Desc
+	rewards     *prometheus.Desc
+	lastWon     *prometheus.Desc
+	scanHeight  *prometheus.Desc
+
+	opts  lotusBlocksOptions
+	maddr address.Address
+	ep    endpoint
+
+	mu sync.Mutex
+	// 已扫描到的高度，启动后从链头开始扫描
+	scanned abi.ChainEpoch
+	// 链头附近出现的本矿工区块，达到确认高度后再统计是否成为孤块
+	pending       map[abi.ChainEpoch][]cid.Cid
+	wonTotal      uint64
+	orphanedTotal uint64
+	expectedTotal float64
+	rewardsTotal  float64
+	lastWonTime   uint64
+}
+
+type lotusBlocksOptions struct {
+	minerOption `mapstructure:",squash"`
+	// 区块经过该高度数后仍在链上才计为出块，否则计为孤块
+	Confidence int `mapstructure:"confidence"`
+	// 出块间隔，用于计算每天的期望出块数
+	BlockDelay time.Duration `mapstructure:"block-delay"`
+}
+
+func init() {
+	registerCollector("lotus-blocks", chainEndpoint, defaultEnabled, NewLotusBlocksCollector)
+}
+
+func NewLotusBlocksCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	opts := lotusBlocksOptions{
+		Confidence: 5,
+		BlockDelay: builtin.EpochDurationSeconds * time.Second,
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-blocks options: %w", err)
+	}
+	maddr, err := opts.minerAddress()
+	if err != nil {
+		return nil, err
+	}
+	if maddr == address.Undef && ep.miner == nil {
+		logger.Warnf("lotus-blocks has no miner address, set the miner option or configure lotus.miner, no block metrics will be reported")
+	}
+
+	return &lotusBlocksCollector{
+		eligible: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "eligible"),
+			"Whether the miner is eligible to mine the next epoch according to MinerGetBaseInfo.",
+			[]string{"miner"}, ep.labels(),
+		),
+		expectedDay: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "expected_per_day"),
+			"Expected blocks per day from the miner share of network power.",
+			[]string{"miner"}, ep.labels(),
+		),
+		expected: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "expected_total"),
+			"Expected blocks over the scanned epochs since the collector started.",
+			[]string{"miner"}, ep.labels(),
+		),
+		won: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "won_total"),
+			"Blocks mined by the miner that stayed on chain after confidence epochs.",
+			[]string{"miner"}, ep.labels(),
+		),
+		orphaned: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "orphaned_total"),
+			"Blocks mined by the miner that were seen near the head but dropped from the chain.",
+			[]string{"miner"}, ep.labels(),
+		),
+		missed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "missed"),
+			"Expected blocks minus won and unconfirmed blocks since the collector started, late or never included blocks show up here; negative when the miner is lucky.",
+			[]string{"miner"}, ep.labels(),
+		),
+		rewards: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "rewards_total"),
+			"Estimated block rewards in attoFIL, gas rewards are not included.",
+			[]string{"miner"}, ep.labels(),
+		),
+		lastWon: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "last_won_timestamp_seconds"),
+			"Timestamp of the last block mined by the miner.",
+			[]string{"miner"}, ep.labels(),
+		),
+		scanHeight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "blocks", "scan_height"),
+			"Chain height scanned for blocks mined by the miner.",
+			[]string{"miner"}, ep.labels(),
+		),
+		opts:    opts,
+		maddr:   maddr,
+		ep:      ep,
+		pending: make(map[abi.ChainEpoch][]cid.Cid),
+	}, nil
+}
+
+func (lc *lotusBlocksCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.daemon.context()
+	defer cancel()
+	client, err := lc.ep.daemon.daemon()
+	if err != nil {
+		return err
+	}
+	maddr, err := lc.ep.minerAddress(ctx, lc.maddr)
+	if err != nil || maddr == address.Undef {
+		return err
+	}
+	m := maddr.String()
+
+	head, err := client.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+	base, err := client.MinerGetBaseInfo(ctx, maddr, head.Height+1, head.key())
+	if err != nil {
+		return err
+	}
+	eligible := base != nil && (base.EligibleForMining == nil || *base.EligibleForMining)
+	var perEpoch float64
+	if base != nil {
+		if network := bigToFloat(base.NetworkPower); network > 0 {
+			perEpoch = bigToFloat(base.MinerPower) / network * float64(builtin.ExpectedLeadersPerEpoch)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(lc.eligible, prometheus.GaugeValue, boolToFloat(eligible), m)
+	if lc.opts.BlockDelay > 0 {
+		ch <- prometheus.MustNewConstMetric(lc.expectedDay, prometheus.GaugeValue, perEpoch*float64(24*time.Hour/lc.opts.BlockDelay), m)
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	err = lc.scan(ctx, client, maddr, head, perEpoch)
+	if err == nil {
+		err = lc.confirm(ctx, client, maddr, head)
+	}
+
+	ch <- prometheus.MustNewConstMetric(lc.expected, prometheus.CounterValue, lc.expectedTotal, m)
+	ch <- prometheus.MustNewConstMetric(lc.won, prometheus.CounterValue, float64(lc.wonTotal), m)
+	ch <- prometheus.MustNewConstMetric(lc.orphaned, prometheus.CounterValue, float64(lc.orphanedTotal), m)
+	ch <- prometheus.MustNewConstMetric(lc.missed, prometheus.GaugeValue, lc.expectedTotal-float64(lc.wonTotal+lc.pendingCount()), m)
+	ch <- prometheus.MustNewConstMetric(lc.rewards, prometheus.CounterValue, lc.rewardsTotal, m)
+	ch <- prometheus.MustNewConstMetric(lc.scanHeight, prometheus.GaugeValue, float64(lc.scanned), m)
+	if lc.lastWonTime > 0 {
+		ch <- prometheus.MustNewConstMetric(lc.lastWon, prometheus.GaugeValue, float64(lc.lastWonTime), m)
+	}
+	return err
+}
+
+// 扫描新的高度，记录本矿工出的区块
+func (lc *lotusBlocksCollector) scan(ctx context.Context, client *Client, maddr address.Address, head *chainTipSet, perEpoch float64) error {
+	if lc.scanned == 0 {
+		lc.scanned = head.Height
+		return nil
+	}
+	from := lc.scanned + 1
+	if head.Height-from > maxScanEpochs {
+		from = head.Height - maxScanEpochs
+	}
+	for h := from; h <= head.Height; h++ {
+		ts, err := client.ChainGetTipSetByHeight(ctx, h, head.key())
+		if err != nil {
+			return err
+		}
+		lc.expectedTotal += perEpoch
+		lc.scanned = h
+		// 空块高度返回之前的 tipset
+		if ts.Height != h {
+			continue
+		}
+		if mine := mined(ts, maddr); len(mine) > 0 {
+			lc.pending[h] = mine
+		}
+	}
+	return nil
+}
+
+// 达到确认高度后检查区块是否仍在链上
+func (lc *lotusBlocksCollector) confirm(ctx context.Context, client *Client, maddr address.Address, head *chainTipSet) error {
+	var reward *rewardActorState
+	for h, cids := range lc.pending {
+		if h > head.Height-abi.ChainEpoch(lc.opts.Confidence) {
+			continue
+		}
+		if reward == nil {
+			reward = &rewardActorState{}
+			if err := readState(ctx, client, builtin.RewardActorAddr, reward); err != nil {
+				return err
+			}
+		}
+		ts, err := client.ChainGetTipSetByHeight(ctx, h, head.key())
+		if err != nil {
+			return err
+		}
+		onChain := make(map[cid.Cid]bool)
+		if ts.Height == h {
+			for _, c := range mined(ts, maddr) {
+				onChain[c] = true
+			}
+		}
+		for _, c := range cids {
+			if !onChain[c] {
+				lc.orphanedTotal++
+				continue
+			}
+			lc.wonTotal++
+			lc.rewardsTotal += reward.blockReward()
+			if t := ts.minTimestamp(); t > lc.lastWonTime {
+				lc.lastWonTime = t
+			}
+		}
+		delete(lc.pending, h)
+	}
+	return nil
+}
+
+// 等待确认的区块数
+func (lc *lotusBlocksCollector) pendingCount() uint64 {
+	var n uint64
+	for _, cids := range lc.pending {
+		n += uint64(len(cids))
+	}
+	return n
+}
+
+func mined(ts *chainTipSet, maddr address.Address) []cid.Cid {
+	var cids []cid.Cid
+	for i, b := range ts.Blocks {
+		if b.Miner == maddr && i < len(ts.Cids) {
+			cids = append(cids, ts.Cids[i])
+		}
+	}
+	return cids
+}
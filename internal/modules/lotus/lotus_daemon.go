@@ -1,59 +1,193 @@
 package lotus
 
 import (
+	"context"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
-	"github.com/filecoin-project/go-jsonrpc"
+	"fmt"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/prometheus/client_golang/prometheus"
-	"strings"
+	"strconv"
+	"sync"
+	"time"
 )
 
 type lotusDaemonCollector struct {
-	version    *prometheus.Desc
-	peersAddr  *prometheus.Desc
-	peersCount *prometheus.Desc
+	version         *prometheus.Desc
+	peersCount      *prometheus.Desc
+	peers           *prometheus.Desc
+	peerScore       *prometheus.Desc
+	peersByAgent    *prometheus.Desc
+	peersDirection  *prometheus.Desc
+	peerInfo        *prometheus.Desc
+	peerScoreDetail *prometheus.Desc
 
-	client *Client
-	closer jsonrpc.ClientCloser
+	chainHeight         *prometheus.Desc
+	chainHeadTimestamp  *prometheus.Desc
+	chainHeadAge        *prometheus.Desc
+	chainExpectedHeight *prometheus.Desc
+	chainLag            *prometheus.Desc
+	chainStale          *prometheus.Desc
+	baseFee             *prometheus.Desc
+	syncStage           *prometheus.Desc
+	syncHeight          *prometheus.Desc
+	syncTarget          *prometheus.Desc
+
+	opts   lotusDaemonOptions
+	ep     endpoint
+	logger log.Logger
+
+	// Update 可能被 /metrics 和推送同时调用
+	mu      sync.Mutex
+	genesis uint64
+
+	// daemon 不支持 NetAgentVersion 时只提示一次
+	agentOnce sync.Once
+}
+
+type lotusDaemonOptions struct {
+	// 出块间隔，默认为 25s
+	BlockDelay time.Duration `mapstructure:"block-delay"`
+	// 链头落后超过该区块数时视为停止同步
+	StaleEpochs int `mapstructure:"stale-epochs"`
+	// 输出每个节点的指标，节点很多时会产生大量序列
+	PeerDetails bool `mapstructure:"peer-details"`
+	// 逐个节点查询客户端版本，需要新版本 lotus
+	PeerAgents bool `mapstructure:"peer-agents"`
+	// 逐个节点查询地址簿推断连接方向
+	PeerDirection bool `mapstructure:"peer-direction"`
+	// 查询客户端版本和连接方向的最多节点数，为 0 时查询全部
+	MaxPeerLookups int `mapstructure:"max-peer-lookups"`
 }
 
 func init() {
-	registerCollector("lotus-daemon", NewLotusDaemonCollector)
+	registerCollector("lotus-daemon", daemonEndpoint, defaultEnabled, NewLotusDaemonCollector)
 }
 
-func NewLotusDaemonCollector(logger log.Logger) (gateway.Collector, error) {
-	client := &Client{}
-	closer, err := InitClient(client)
-	if err != nil {
-		return nil, err
+func NewLotusDaemonCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	opts := lotusDaemonOptions{
+		BlockDelay:     builtin.EpochDurationSeconds * time.Second,
+		StaleEpochs:    5,
+		MaxPeerLookups: 500,
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-daemon options: %w", err)
 	}
 
 	version := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "daemon", "version"),
 		"lotus daemon version.",
 		[]string{"version"},
-		nil,
-	)
-
-	peersAddr := prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "daemon", "paddr"),
-		"lotus daemon peers addr.",
-		[]string{"id", "ip", "port"},
-		nil,
+		ep.labels(),
 	)
 
 	peersCount := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, "daemon", "pcount"),
 		"lotus daemon peers count.",
 		nil,
-		nil,
+		ep.labels(),
 	)
 
-	return &lotusDaemonCollector{client: client, closer: closer, version: version, peersAddr: peersAddr, peersCount: peersCount}, nil
+	return &lotusDaemonCollector{
+		ep:         ep,
+		logger:     logger,
+		opts:       opts,
+		version:    version,
+		peersCount: peersCount,
+		peers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "peers"),
+			"lotus daemon connected peers by network and transport protocol.",
+			[]string{"network", "transport"}, ep.labels(),
+		),
+		peerScore: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "peer_score"),
+			"Distribution of gossipsub scores of connected peers.",
+			nil, ep.labels(),
+		),
+		peersByAgent: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "peers_by_agent"),
+			"lotus daemon connected peers by agent version, only with peer-agents.",
+			[]string{"agent"}, ep.labels(),
+		),
+		peersDirection: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "peers_by_direction"),
+			"lotus daemon connected peers by estimated connection direction, only with peer-direction.",
+			[]string{"direction"}, ep.labels(),
+		),
+		peerInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "peer_info"),
+			"lotus daemon connected peer, only with peer-details.",
+			[]string{"peer", "network", "transport", "agent"}, ep.labels(),
+		),
+		peerScoreDetail: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "peer_pubsub_score"),
+			"Gossipsub score of a connected peer, only with peer-details.",
+			[]string{"peer"}, ep.labels(),
+		),
+		chainHeight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_height"),
+			"lotus daemon chain head height.",
+			nil, ep.labels(),
+		),
+		chainHeadTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_head_timestamp_seconds"),
+			"lotus daemon chain head timestamp.",
+			nil, ep.labels(),
+		),
+		chainHeadAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_head_age_seconds"),
+			"Seconds since the lotus daemon chain head timestamp.",
+			nil, ep.labels(),
+		),
+		chainExpectedHeight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_expected_height"),
+			"Chain height expected from genesis time and block delay.",
+			nil, ep.labels(),
+		),
+		chainLag: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_lag_epochs"),
+			"Epochs the lotus daemon chain head is behind the wall-clock epoch.",
+			nil, ep.labels(),
+		),
+		chainStale: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_stale"),
+			"Whether the lotus daemon chain head is older than stale-epochs blocks.",
+			nil, ep.labels(),
+		),
+		baseFee: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "chain_base_fee"),
+			"Parent base fee of the chain head in attoFIL, only reported by newer lotus.",
+			nil, ep.labels(),
+		),
+		syncStage: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "sync_stage"),
+			"lotus daemon sync worker stage.",
+			[]string{"worker", "stage"}, ep.labels(),
+		),
+		syncHeight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "sync_height"),
+			"lotus daemon sync worker height.",
+			[]string{"worker"}, ep.labels(),
+		),
+		syncTarget: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "daemon", "sync_target_height"),
+			"lotus daemon sync worker target height.",
+			[]string{"worker"}, ep.labels(),
+		),
+	}, nil
 }
 
 func (lc *lotusDaemonCollector) Update(ch chan<- prometheus.Metric) error {
-	v, err := lc.client.Version()
+	ctx, cancel := lc.ep.daemon.context()
+	defer cancel()
+	client, err := lc.ep.daemon.daemon()
+	if err != nil {
+		return err
+	}
+
+	v, err := client.Version(ctx)
 	if err != nil {
 		return err
 	}
@@ -65,53 +199,220 @@ func (lc *lotusDaemonCollector) Update(ch chan<- prometheus.Metric) error {
 		v.Version,
 	)
 
-	ps, err := lc.client.NetPeers()
+	// 链和节点指标互不影响，同步中或部分接口不可用时仍输出节点指标
+	chainErr := lc.updateChain(ctx, client, ch)
+	peersCtx, peersCancel := lc.ep.daemon.context()
+	defer peersCancel()
+	peersErr := lc.updatePeers(peersCtx, client, ch)
+	switch {
+	case chainErr != nil && peersErr != nil:
+		return fmt.Errorf("update chain: %v; update peers: %v", chainErr, peersErr)
+	case chainErr != nil:
+		return fmt.Errorf("update chain: %w", chainErr)
+	case peersErr != nil:
+		return fmt.Errorf("update peers: %w", peersErr)
+	}
+	return nil
+}
+
+func (lc *lotusDaemonCollector) updateChain(ctx context.Context, client *Client, ch chan<- prometheus.Metric) error {
+	genesis, err := lc.genesisTime(ctx, client)
 	if err != nil {
 		return err
 	}
 
-	sc, err := lc.client.NetPubsubScores()
+	head, err := client.ChainHead(ctx)
 	if err != nil {
 		return err
 	}
+	now := time.Now()
+	ts := head.minTimestamp()
+	age := now.Sub(time.Unix(int64(ts), 0)).Seconds()
+	expected := expectedHeight(genesis, lc.opts.BlockDelay, now)
+	var stale float64
+	if age > (time.Duration(lc.opts.StaleEpochs) * lc.opts.BlockDelay).Seconds() {
+		stale = 1
+	}
 
-	scm := make(map[string]float64)
-	for i := range ps {
-		if i < len(sc) {
-			scm[sc[i].ID.String()] = sc[i].Score
+	ch <- prometheus.MustNewConstMetric(lc.chainHeight, prometheus.GaugeValue, float64(head.Height))
+	ch <- prometheus.MustNewConstMetric(lc.chainHeadTimestamp, prometheus.GaugeValue, float64(ts))
+	ch <- prometheus.MustNewConstMetric(lc.chainHeadAge, prometheus.GaugeValue, age)
+	ch <- prometheus.MustNewConstMetric(lc.chainExpectedHeight, prometheus.GaugeValue, float64(expected))
+	ch <- prometheus.MustNewConstMetric(lc.chainLag, prometheus.GaugeValue, float64(expected-head.Height))
+	ch <- prometheus.MustNewConstMetric(lc.chainStale, prometheus.GaugeValue, stale)
+	if fee := head.baseFee(); fee != nil {
+		ch <- prometheus.MustNewConstMetric(lc.baseFee, prometheus.GaugeValue, bigToFloat(*fee))
+	}
+
+	state, err := client.SyncState(ctx)
+	if err != nil {
+		return err
+	}
+	for i, ss := range state.ActiveSyncs {
+		worker := strconv.Itoa(i)
+		ch <- prometheus.MustNewConstMetric(lc.syncStage, prometheus.GaugeValue, 1, worker, syncStageString(ss.Stage))
+		ch <- prometheus.MustNewConstMetric(lc.syncHeight, prometheus.GaugeValue, float64(ss.Height), worker)
+		if ss.Target != nil {
+			ch <- prometheus.MustNewConstMetric(lc.syncTarget, prometheus.GaugeValue, float64(ss.Target.Height()), worker)
 		}
 	}
+	return nil
+}
 
-	if len(ps) > 0 && len(sc) > 0 {
-		for i := range ps {
+// 按网络和传输协议、分数区间汇总节点，逐个节点的指标需要开启 peer-details
+func (lc *lotusDaemonCollector) updatePeers(ctx context.Context, client *Client, ch chan<- prometheus.Metric) error {
+	ps, err := client.NetPeers(ctx)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.peersCount, prometheus.GaugeValue, float64(len(ps)))
 
-			addrs := ps[i].Addrs[0]
-			if addrs != nil {
-				addrsArr := strings.Split(addrs.String(), "/")
+	type transportKey struct {
+		network, transport string
+	}
+	transports := make(map[transportKey]int)
+	keys := make([]transportKey, len(ps))
+	for i, p := range ps {
+		// 没有地址的节点同样计数
+		k := transportKey{network: unknownValue, transport: unknownValue}
+		if len(p.Addrs) > 0 {
+			k.network, k.transport = peerTransport(p.Addrs[0])
+		}
+		transports[k]++
+		keys[i] = k
+	}
+	for k, count := range transports {
+		ch <- prometheus.MustNewConstMetric(lc.peers, prometheus.GaugeValue, float64(count), k.network, k.transport)
+	}
 
-				ch <- prometheus.MustNewConstMetric(
-					lc.peersAddr,
-					prometheus.GaugeValue,
-					scm[ps[i].ID.String()],
-					ps[i].ID.String(),
-					addrsArr[2],
-					addrsArr[4],
-				)
+	scores, err := client.NetPubsubScores(ctx)
+	if err != nil {
+		return err
+	}
+	buckets := make(map[float64]uint64, len(peerScoreBuckets))
+	var count uint64
+	var sum float64
+	for _, s := range scores {
+		v, ok := s.value()
+		if !ok {
+			continue
+		}
+		count++
+		sum += v
+		for _, b := range peerScoreBuckets {
+			if v <= b {
+				buckets[b]++
 			}
 		}
-	} else {
-		ch <- prometheus.MustNewConstMetric(
-			lc.peersCount,
-			prometheus.GaugeValue,
-			0,
-		)
+		if lc.opts.PeerDetails {
+			ch <- prometheus.MustNewConstMetric(lc.peerScoreDetail, prometheus.GaugeValue, v, s.ID.String())
+		}
 	}
+	ch <- prometheus.MustNewConstHistogram(lc.peerScore, count, sum, buckets)
 
-	ch <- prometheus.MustNewConstMetric(
-		lc.peersCount,
-		prometheus.GaugeValue,
-		float64(len(ps)),
-	)
-
+	// 逐个节点的查询放在最后，单独计时，超时只影响客户端版本和连接方向
+	var lookups peerLookups
+	if lc.opts.PeerAgents || lc.opts.PeerDirection {
+		lookupCtx, cancel := lc.ep.daemon.context()
+		lookups = lc.lookupPeers(lookupCtx, client, ps)
+		cancel()
+		for agent, count := range lookups.agentCounts {
+			ch <- prometheus.MustNewConstMetric(lc.peersByAgent, prometheus.GaugeValue, float64(count), agent)
+		}
+		for direction, count := range lookups.directions {
+			ch <- prometheus.MustNewConstMetric(lc.peersDirection, prometheus.GaugeValue, float64(count), direction)
+		}
+	}
+	if lc.opts.PeerDetails {
+		for i, p := range ps {
+			agent, ok := lookups.agents[p.ID]
+			if !ok {
+				agent = unknownValue
+			}
+			ch <- prometheus.MustNewConstMetric(lc.peerInfo, prometheus.GaugeValue, 1, p.ID.String(), keys[i].network, keys[i].transport, agent)
+		}
+	}
 	return nil
 }
+
+// 逐个节点查询的结果
+type peerLookups struct {
+	agents      map[peer.ID]string
+	agentCounts map[string]int
+	directions  map[string]int
+}
+
+// 逐个节点查询客户端版本和连接方向，最多查询 max-peer-lookups 个节点，超时后返回已查询到的结果
+func (lc *lotusDaemonCollector) lookupPeers(ctx context.Context, client *Client, ps []netPeer) peerLookups {
+	l := peerLookups{
+		agents:      make(map[peer.ID]string),
+		agentCounts: make(map[string]int),
+		directions:  make(map[string]int),
+	}
+	if lc.opts.MaxPeerLookups > 0 && len(ps) > lc.opts.MaxPeerLookups {
+		ps = ps[:lc.opts.MaxPeerLookups]
+	}
+	agents := lc.opts.PeerAgents
+	looked := 0
+	for _, p := range ps {
+		if ctx.Err() != nil {
+			break
+		}
+		if agents {
+			v, err := client.NetAgentVersion(ctx, p.ID)
+			if err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				lc.agentOnce.Do(func() {
+					lc.logger.Infof("lotus daemon does not support NetAgentVersion, peer agents are not collected: %v", err)
+				})
+				agents = false
+			} else {
+				agent := agentName(v)
+				l.agents[p.ID] = agent
+				l.agentCounts[agent]++
+			}
+		}
+		if lc.opts.PeerDirection && len(p.Addrs) > 0 {
+			direction := peerDirection(ctx, client, p)
+			if ctx.Err() != nil {
+				break
+			}
+			l.directions[direction]++
+		}
+		looked++
+	}
+	if ctx.Err() != nil {
+		lc.logger.Warnf("lotus daemon peer lookups timed out after %d of %d peers, lower max-peer-lookups or increase timeout", looked, len(ps))
+	}
+	return l
+}
+
+// lotus 不返回连接方向，主动连接时对方地址为其监听地址，会出现在地址簿中，被动连接时为临时端口
+func peerDirection(ctx context.Context, client *Client, p netPeer) string {
+	info, err := client.NetFindPeer(ctx, p.ID)
+	if err != nil {
+		return unknownValue
+	}
+	for _, addr := range info.Addrs {
+		if addr == p.Addrs[0] {
+			return "outbound"
+		}
+	}
+	return "inbound"
+}
+
+// 创世区块时间，成功获取后缓存
+func (lc *lotusDaemonCollector) genesisTime(ctx context.Context, client *Client) (uint64, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.genesis == 0 {
+		genesis, err := client.ChainGetGenesis(ctx)
+		if err != nil {
+			return 0, err
+		}
+		lc.genesis = genesis.minTimestamp()
+	}
+	return lc.genesis, nil
+}
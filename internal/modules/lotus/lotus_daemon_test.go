@@ -0,0 +1,50 @@
+package lotus
+
+import (
+	"context"
+	"errors"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/log"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestDaemonUpdatePeersWithoutChain(t *testing.T) {
+	client := &Client{
+		Version: func(ctx context.Context) (api.Version, error) {
+			return api.Version{Version: "0.4.1"}, nil
+		},
+		ChainGetGenesis: func(ctx context.Context) (*chainTipSet, error) {
+			return nil, errors.New("chain not synced")
+		},
+		NetPeers: func(ctx context.Context) ([]netPeer, error) {
+			return testPeers(3), nil
+		},
+		NetPubsubScores: func(ctx context.Context) ([]pubsubScore, error) {
+			return nil, nil
+		},
+	}
+	conn := newRpcConn(daemonEndpoint, "", config.Endpoint{}, "")
+	conn.out = client
+	c, err := NewLotusDaemonCollector(log.NopLogger(), config.Collector{}, endpoint{daemon: conn})
+	require.NoError(t, err)
+
+	ch := make(chan prometheus.Metric, 100)
+	err = c.Update(ch)
+	close(ch)
+	// 链指标出错时仍输出版本和节点指标
+	assert.Error(t, err)
+	var names []string
+	for m := range ch {
+		names = append(names, m.Desc().String())
+	}
+	all := strings.Join(names, "\n")
+	assert.Contains(t, all, `"lotus_daemon_version"`)
+	assert.Contains(t, all, `"lotus_daemon_pcount"`)
+	assert.Contains(t, all, `"lotus_daemon_peers"`)
+	assert.Contains(t, all, `"lotus_daemon_peer_score"`)
+}
@@ -0,0 +1,332 @@
+package lotus
+
+import (
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/filecoin-project/go-fil-markets/retrievalmarket"
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 与 storagemarket.MinerDeal 的 JSON 一致，只读取需要的字段
+type minerDeal struct {
+	Proposal struct {
+		PieceSize abi.PaddedPieceSize
+	}
+	ProposalCid cid.Cid
+	State       uint64
+}
+
+// 与 storagemarket.SignedStorageAsk 的 JSON 一致
+type storageAsk struct {
+	Ask *struct {
+		Price        types.BigInt
+		MinPieceSize abi.PaddedPieceSize
+		MaxPieceSize abi.PaddedPieceSize
+		Expiry       abi.ChainEpoch
+	}
+}
+
+// 新版本 lotus MarketListRetrievalDeals 返回的检索订单
+type retrievalDeal struct {
+	Status    uint64
+	TotalSent uint64
+}
+
+// 新版本 lotus MarketListDataTransfers 返回的数据传输
+type dataTransfer struct {
+	TransferID  uint64
+	Status      uint64
+	IsSender    bool
+	OtherPeer   string
+	Transferred uint64
+}
+
+// 新版本 lotus 使用的 go-data-transfer 状态，lotus v0.4.1 没有数据传输接口
+var transferStatuses = map[uint64]string{
+	0:  "Requested",
+	1:  "Ongoing",
+	2:  "TransferFinished",
+	3:  "ResponderCompleted",
+	4:  "Finalizing",
+	5:  "Completing",
+	6:  "Completed",
+	7:  "Failing",
+	8:  "Failed",
+	9:  "Cancelling",
+	10: "Cancelled",
+	11: "InitiatorPaused",
+	12: "ResponderPaused",
+	13: "BothPaused",
+	14: "ResponderFinalizing",
+	15: "ResponderFinalizingTransferFinished",
+	16: "ChannelNotFoundError",
+}
+
+const transferOngoing = 1
+
+func statusName(names map[uint64]string, status uint64) string {
+	if name, ok := names[status]; ok {
+		return name
+	}
+	return strconv.FormatUint(status, 10)
+}
+
+// 状态或传输进度最后一次变化的时间
+type progress struct {
+	value uint64
+	since time.Time
+}
+
+type lotusMarketCollector struct {
+	deals           *prometheus.Desc
+	dealBytes       *prometheus.Desc
+	dealsStalled    *prometheus.Desc
+	retrievals      *prometheus.Desc
+	retrievalBytes  *prometheus.Desc
+	transfers       *prometheus.Desc
+	transferBytes   *prometheus.Desc
+	transferStalled *prometheus.Desc
+	askPrice        *prometheus.Desc
+	askMinSize      *prometheus.Desc
+	askMaxSize      *prometheus.Desc
+	askExpiry       *prometheus.Desc
+
+	opts   lotusMarketOptions
+	ep     endpoint
+	logger log.Logger
+
+	mu        sync.Mutex
+	dealState map[cid.Cid]progress
+	transfer  map[string]progress
+
+	// miner 不支持检索订单和数据传输接口时只提示一次
+	retrievalOnce sync.Once
+	transferOnce  sync.Once
+}
+
+type lotusMarketOptions struct {
+	// 订单状态或传输进度超过该时间没有变化时视为停滞
+	StallTimeout time.Duration `mapstructure:"stall-timeout"`
+}
+
+// 订单状态名称按 lotus v0.4.1 使用的 go-fil-markets 版本，新版本 lotus 的状态编号可能不同
+var retrievalStatuses = make(map[uint64]string)
+
+func init() {
+	for k, v := range retrievalmarket.DealStatuses {
+		retrievalStatuses[uint64(k)] = v
+	}
+	registerCollector("lotus-market", minerEndpoint, defaultDisabled, NewLotusMarketCollector)
+}
+
+func NewLotusMarketCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	opts := lotusMarketOptions{StallTimeout: time.Hour}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-market options: %w", err)
+	}
+
+	return &lotusMarketCollector{
+		deals: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "deals"),
+			"Incomplete storage deals by state.",
+			[]string{"state"}, ep.labels(),
+		),
+		dealBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "deal_bytes"),
+			"Padded piece size of incomplete storage deals by state.",
+			[]string{"state"}, ep.labels(),
+		),
+		dealsStalled: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "deals_stalled"),
+			"Incomplete storage deals whose state has not changed for stall-timeout.",
+			[]string{"state"}, ep.labels(),
+		),
+		retrievals: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "retrieval_deals"),
+			"Retrieval deals by status, only reported by newer lotus.",
+			[]string{"status"}, ep.labels(),
+		),
+		retrievalBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "retrieval_sent_bytes"),
+			"Bytes sent for retrieval deals by status, only reported by newer lotus.",
+			[]string{"status"}, ep.labels(),
+		),
+		transfers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "transfers"),
+			"Data transfers by direction and status, only reported by newer lotus.",
+			[]string{"direction", "status"}, ep.labels(),
+		),
+		transferBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "transfer_bytes"),
+			"Bytes transferred by ongoing data transfers, only reported by newer lotus.",
+			[]string{"direction"}, ep.labels(),
+		),
+		transferStalled: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "transfers_stalled"),
+			"Ongoing data transfers without progress for stall-timeout, only reported by newer lotus.",
+			[]string{"direction"}, ep.labels(),
+		),
+		askPrice: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "ask_price"),
+			"Storage ask price in attoFIL per GiB per epoch.",
+			nil, ep.labels(),
+		),
+		askMinSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "ask_min_piece_size_bytes"),
+			"Storage ask minimum piece size.",
+			nil, ep.labels(),
+		),
+		askMaxSize: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "ask_max_piece_size_bytes"),
+			"Storage ask maximum piece size.",
+			nil, ep.labels(),
+		),
+		askExpiry: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "market", "ask_expiry_epoch"),
+			"Epoch the storage ask expires.",
+			nil, ep.labels(),
+		),
+		opts:      opts,
+		ep:        ep,
+		logger:    logger,
+		dealState: make(map[cid.Cid]progress),
+		transfer:  make(map[string]progress),
+	}, nil
+}
+
+func (lc *lotusMarketCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.miner.context()
+	defer cancel()
+	minerClient, err := lc.ep.miner.miner()
+	if err != nil {
+		return err
+	}
+
+	ask, err := minerClient.MarketGetAsk(ctx)
+	if err != nil {
+		return err
+	}
+	if ask != nil && ask.Ask != nil {
+		ch <- prometheus.MustNewConstMetric(lc.askPrice, prometheus.GaugeValue, bigToFloat(ask.Ask.Price))
+		ch <- prometheus.MustNewConstMetric(lc.askMinSize, prometheus.GaugeValue, float64(ask.Ask.MinPieceSize))
+		ch <- prometheus.MustNewConstMetric(lc.askMaxSize, prometheus.GaugeValue, float64(ask.Ask.MaxPieceSize))
+		ch <- prometheus.MustNewConstMetric(lc.askExpiry, prometheus.GaugeValue, float64(ask.Ask.Expiry))
+	}
+
+	deals, err := minerClient.MarketListIncompleteDeals(ctx)
+	if err != nil {
+		return err
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	now := time.Now()
+
+	counts := make(map[string]int)
+	bytes := make(map[string]uint64)
+	stalled := make(map[string]int)
+	seen := make(map[cid.Cid]progress)
+	for _, d := range deals {
+		state := statusName(storagemarket.DealStates, d.State)
+		counts[state]++
+		bytes[state] += uint64(d.Proposal.PieceSize)
+
+		p, ok := lc.dealState[d.ProposalCid]
+		if !ok || p.value != d.State {
+			p = progress{value: d.State, since: now}
+		}
+		seen[d.ProposalCid] = p
+		if now.Sub(p.since) > lc.opts.StallTimeout {
+			stalled[state]++
+		}
+	}
+	// 已完成的订单不再记录
+	lc.dealState = seen
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(lc.deals, prometheus.GaugeValue, float64(count), state)
+		ch <- prometheus.MustNewConstMetric(lc.dealBytes, prometheus.GaugeValue, float64(bytes[state]), state)
+		ch <- prometheus.MustNewConstMetric(lc.dealsStalled, prometheus.GaugeValue, float64(stalled[state]), state)
+	}
+
+	retrievals, err := minerClient.MarketListRetrievalDeals(ctx)
+	if err != nil {
+		lc.retrievalOnce.Do(func() {
+			lc.logger.Infof("lotus miner does not support MarketListRetrievalDeals, retrieval deals are not collected: %v", err)
+		})
+	} else {
+		lc.updateRetrievals(ch, retrievals)
+	}
+
+	transfers, err := minerClient.MarketListDataTransfers(ctx)
+	if err != nil {
+		lc.transferOnce.Do(func() {
+			lc.logger.Infof("lotus miner does not support MarketListDataTransfers, data transfers are not collected: %v", err)
+		})
+	} else {
+		lc.updateTransfers(ch, transfers, now)
+	}
+	return nil
+}
+
+func (lc *lotusMarketCollector) updateRetrievals(ch chan<- prometheus.Metric, deals []retrievalDeal) {
+	counts := make(map[string]int)
+	sent := make(map[string]uint64)
+	for _, d := range deals {
+		status := statusName(retrievalStatuses, d.Status)
+		counts[status]++
+		sent[status] += d.TotalSent
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(lc.retrievals, prometheus.GaugeValue, float64(count), status)
+		ch <- prometheus.MustNewConstMetric(lc.retrievalBytes, prometheus.GaugeValue, float64(sent[status]), status)
+	}
+}
+
+// 调用时已持有 lc.mu
+func (lc *lotusMarketCollector) updateTransfers(ch chan<- prometheus.Metric, transfers []dataTransfer, now time.Time) {
+	type statusKey struct {
+		direction, status string
+	}
+	counts := make(map[statusKey]int)
+	bytes := map[string]uint64{"send": 0, "receive": 0}
+	stalled := map[string]int{"send": 0, "receive": 0}
+	seen := make(map[string]progress)
+	for _, t := range transfers {
+		direction := "receive"
+		if t.IsSender {
+			direction = "send"
+		}
+		counts[statusKey{direction: direction, status: statusName(transferStatuses, t.Status)}]++
+		if t.Status != transferOngoing {
+			continue
+		}
+		bytes[direction] += t.Transferred
+
+		key := t.OtherPeer + "/" + strconv.FormatUint(t.TransferID, 10)
+		p, ok := lc.transfer[key]
+		if !ok || p.value != t.Transferred {
+			p = progress{value: t.Transferred, since: now}
+		}
+		seen[key] = p
+		if now.Sub(p.since) > lc.opts.StallTimeout {
+			stalled[direction]++
+		}
+	}
+	lc.transfer = seen
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(lc.transfers, prometheus.GaugeValue, float64(count), k.direction, k.status)
+	}
+	for direction, b := range bytes {
+		ch <- prometheus.MustNewConstMetric(lc.transferBytes, prometheus.GaugeValue, float64(b), direction)
+		ch <- prometheus.MustNewConstMetric(lc.transferStalled, prometheus.GaugeValue, float64(stalled[direction]), direction)
+	}
+}
@@ -0,0 +1,214 @@
+package lotus
+
+import (
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/prometheus/client_golang/prometheus"
+	"math/big"
+	"sort"
+)
+
+type lotusMinerCollector struct {
+	info         *prometheus.Desc
+	sectors      *prometheus.Desc
+	sectorStates *prometheus.Desc
+	// 统计了状态的扇区数，小于扇区总数时 sector_state 只包含最新的扇区
+	sectorStatesScanned *prometheus.Desc
+	power               *prometheus.Desc
+	networkPower        *prometheus.Desc
+	faults              *prometheus.Desc
+	recoveries          *prometheus.Desc
+	deadline            *prometheus.Desc
+
+	opts   lotusMinerOptions
+	ep     endpoint
+	logger log.Logger
+}
+
+type lotusMinerOptions struct {
+	// 逐个查询扇区状态，扇区很多时可以关闭，只统计扇区总数
+	SectorStates bool `mapstructure:"sector-states"`
+	// 最多查询状态的扇区数，从编号最大的扇区开始查询，为 0 时查询全部
+	MaxSectorStates int `mapstructure:"max-sector-states"`
+}
+
+func init() {
+	registerCollector("lotus-miner", minerEndpoint, defaultEnabled, NewLotusMinerCollector)
+}
+
+func NewLotusMinerCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	opts := lotusMinerOptions{SectorStates: true, MaxSectorStates: 1000}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-miner options: %w", err)
+	}
+
+	return &lotusMinerCollector{
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "info"),
+			"lotus miner address and sector size.",
+			[]string{"miner", "sector_size"}, ep.labels(),
+		),
+		sectors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "sectors"),
+			"lotus miner sectors count.",
+			[]string{"miner"}, ep.labels(),
+		),
+		sectorStates: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "sector_state"),
+			"lotus miner sectors count by state.",
+			[]string{"miner", "state"}, ep.labels(),
+		),
+		sectorStatesScanned: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "sector_states_scanned"),
+			"lotus miner sectors counted in sector_state, newest sectors first.",
+			[]string{"miner"}, ep.labels(),
+		),
+		power: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "power_bytes"),
+			"lotus miner raw and quality-adjusted power.",
+			[]string{"miner", "type"}, ep.labels(),
+		),
+		networkPower: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "network_power_bytes"),
+			"network raw and quality-adjusted power.",
+			[]string{"type"}, ep.labels(),
+		),
+		faults: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "faults"),
+			"lotus miner faulty sectors count.",
+			[]string{"miner"}, ep.labels(),
+		),
+		recoveries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "recoveries"),
+			"lotus miner recovering sectors count.",
+			[]string{"miner"}, ep.labels(),
+		),
+		deadline: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "deadline_index"),
+			"lotus miner current WindowPoSt deadline index.",
+			[]string{"miner"}, ep.labels(),
+		),
+		opts:   opts,
+		ep:     ep,
+		logger: logger,
+	}, nil
+}
+
+func (lc *lotusMinerCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.miner.context()
+	defer cancel()
+	minerClient, err := lc.ep.miner.miner()
+	if err != nil {
+		return err
+	}
+	client, err := lc.ep.daemon.daemon()
+	if err != nil {
+		return err
+	}
+	maddr, err := lc.ep.miner.actorAddress(ctx)
+	if err != nil {
+		return err
+	}
+	m := maddr.String()
+
+	size, err := minerClient.ActorSectorSize(ctx, maddr)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.info, prometheus.GaugeValue, 1, m, size.ShortString())
+
+	sectors, err := minerClient.SectorsList(ctx)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.sectors, prometheus.GaugeValue, float64(len(sectors)), m)
+
+	power, err := client.StateMinerPower(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.power, prometheus.GaugeValue, bigToFloat(power.MinerPower.RawBytePower), m, "raw")
+	ch <- prometheus.MustNewConstMetric(lc.power, prometheus.GaugeValue, bigToFloat(power.MinerPower.QualityAdjPower), m, "qa")
+	ch <- prometheus.MustNewConstMetric(lc.networkPower, prometheus.GaugeValue, bigToFloat(power.TotalPower.RawBytePower), "raw")
+	ch <- prometheus.MustNewConstMetric(lc.networkPower, prometheus.GaugeValue, bigToFloat(power.TotalPower.QualityAdjPower), "qa")
+
+	faults, err := client.StateMinerFaults(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	faultCount, err := faults.Count()
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.faults, prometheus.GaugeValue, float64(faultCount), m)
+
+	recoveries, err := client.StateMinerRecoveries(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	recoveryCount, err := recoveries.Count()
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.recoveries, prometheus.GaugeValue, float64(recoveryCount), m)
+
+	dl, err := client.StateMinerProvingDeadline(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(lc.deadline, prometheus.GaugeValue, float64(dl.Index), m)
+
+	// 逐个查询扇区状态最慢，放在最后并单独计时，超时不影响其他指标
+	if lc.opts.SectorStates {
+		lc.updateSectorStates(minerClient, sectors, m, ch)
+	}
+	return nil
+}
+
+// 从编号最大的扇区开始查询状态，最多查询 max-sector-states 个，超时后输出已查询到的状态
+func (lc *lotusMinerCollector) updateSectorStates(minerClient *MinerClient, sectors []abi.SectorNumber, m string, ch chan<- prometheus.Metric) {
+	ctx, cancel := lc.ep.miner.context()
+	defer cancel()
+
+	sorted := make([]abi.SectorNumber, len(sectors))
+	copy(sorted, sectors)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	if lc.opts.MaxSectorStates > 0 && len(sorted) > lc.opts.MaxSectorStates {
+		sorted = sorted[:lc.opts.MaxSectorStates]
+	}
+
+	states := make(map[string]int)
+	scanned := 0
+	for _, s := range sorted {
+		if ctx.Err() != nil {
+			break
+		}
+		st, err := minerClient.SectorsStatus(ctx, s)
+		if err != nil {
+			lc.logger.Debugf("get sector %d status err: %v", s, err)
+			continue
+		}
+		states[string(st.State)]++
+		scanned++
+	}
+	if ctx.Err() != nil {
+		lc.logger.Warnf("lotus miner sector status scan timed out after %d of %d sectors, lower max-sector-states or increase timeout", scanned, len(sorted))
+	}
+	for state, count := range states {
+		ch <- prometheus.MustNewConstMetric(lc.sectorStates, prometheus.GaugeValue, float64(count), m, state)
+	}
+	ch <- prometheus.MustNewConstMetric(lc.sectorStatesScanned, prometheus.GaugeValue, float64(scanned), m)
+}
+
+// 链上的大整数转为浮点数，精度损失对监控没有影响
+func bigToFloat(b types.BigInt) float64 {
+	if b.Int == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(b.Int).Float64()
+	return f
+}
@@ -0,0 +1,276 @@
+package lotus
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+// 与 types.SignedMessage 的 JSON 一致，lotus v0.4.1 只有 GasPrice，新版本 lotus 为 GasPremium 和 GasFeeCap
+type pendingMessage struct {
+	Message struct {
+		To         address.Address
+		From       address.Address
+		Nonce      uint64
+		GasPrice   *types.BigInt
+		GasPremium *types.BigInt
+		GasFeeCap  *types.BigInt
+		Method     abi.MethodNum
+	}
+}
+
+// 消息的最高出价，新版本 lotus 为 GasFeeCap
+func (m *pendingMessage) feeCap() *types.BigInt {
+	if m.Message.GasFeeCap != nil {
+		return m.Message.GasFeeCap
+	}
+	return m.Message.GasPrice
+}
+
+var minerMethodNames = map[abi.MethodNum]string{
+	builtin.MethodsMiner.ChangeWorkerAddress:    "ChangeWorkerAddress",
+	builtin.MethodsMiner.ChangePeerID:           "ChangePeerID",
+	builtin.MethodsMiner.SubmitWindowedPoSt:     "SubmitWindowedPoSt",
+	builtin.MethodsMiner.PreCommitSector:        "PreCommitSector",
+	builtin.MethodsMiner.ProveCommitSector:      "ProveCommitSector",
+	builtin.MethodsMiner.ExtendSectorExpiration: "ExtendSectorExpiration",
+	builtin.MethodsMiner.TerminateSectors:       "TerminateSectors",
+	builtin.MethodsMiner.DeclareFaults:          "DeclareFaults",
+	builtin.MethodsMiner.DeclareFaultsRecovered: "DeclareFaultsRecovered",
+	builtin.MethodsMiner.AddLockedFund:          "AddLockedFund",
+	builtin.MethodsMiner.WithdrawBalance:        "WithdrawBalance",
+}
+
+// 方法号只在发往矿工的消息中有意义，其他消息只区分转账
+func methodName(m *pendingMessage, maddr address.Address) string {
+	if m.Message.Method == builtin.MethodSend {
+		return "Send"
+	}
+	if m.Message.To == maddr {
+		if name, ok := minerMethodNames[m.Message.Method]; ok {
+			return name
+		}
+	}
+	return "other"
+}
+
+// 同一地址同一 nonce 的消息被替换时仍视为同一条
+type messageKey struct {
+	from  address.Address
+	nonce uint64
+}
+
+type lotusMpoolCollector struct {
+	pending     *prometheus.Desc
+	oldest      *prometheus.Desc
+	feeCap      *prometheus.Desc
+	premium     *prometheus.Desc
+	belowBase   *prometheus.Desc
+	baseFeeDesc *prometheus.Desc
+
+	addrs []address.Address
+	maddr address.Address
+	ep    endpoint
+
+	// mpool 不返回消息进入的时间，记录第一次发现的时间
+	mu        sync.Mutex
+	firstSeen map[messageKey]time.Time
+}
+
+type lotusMpoolOptions struct {
+	// 需要监控的发送地址
+	Addresses   []string `mapstructure:"addresses"`
+	minerOption `mapstructure:",squash"`
+}
+
+func init() {
+	registerCollector("lotus-mpool", chainEndpoint, defaultEnabled, NewLotusMpoolCollector)
+}
+
+func NewLotusMpoolCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	var opts lotusMpoolOptions
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-mpool options: %w", err)
+	}
+	addrs, err := parseAddresses(opts.Addresses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lotus-mpool addresses: %w", err)
+	}
+	maddr, err := opts.minerAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lotusMpoolCollector{
+		pending: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mpool", "pending"),
+			"Pending messages from own addresses by method.",
+			[]string{"from", "method"}, ep.labels(),
+		),
+		oldest: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mpool", "oldest_pending_seconds"),
+			"Seconds since the oldest pending message from the address was first seen.",
+			[]string{"from"}, ep.labels(),
+		),
+		feeCap: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mpool", "min_fee_cap"),
+			"Lowest gas fee cap of pending messages in attoFIL, gas price on lotus without fee cap.",
+			[]string{"from"}, ep.labels(),
+		),
+		premium: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mpool", "min_gas_premium"),
+			"Lowest gas premium of pending messages in attoFIL, only reported by newer lotus.",
+			[]string{"from"}, ep.labels(),
+		),
+		belowBase: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mpool", "below_base_fee"),
+			"Pending messages whose fee cap is below the current base fee.",
+			[]string{"from"}, ep.labels(),
+		),
+		baseFeeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mpool", "base_fee"),
+			"Current base fee in attoFIL the pending messages are compared with.",
+			nil, ep.labels(),
+		),
+		addrs:     addrs,
+		maddr:     maddr,
+		ep:        ep,
+		firstSeen: make(map[messageKey]time.Time),
+	}, nil
+}
+
+func (lc *lotusMpoolCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.daemon.context()
+	defer cancel()
+	client, err := lc.ep.daemon.daemon()
+	if err != nil {
+		return err
+	}
+	maddr, err := lc.ep.minerAddress(ctx, lc.maddr)
+	if err != nil {
+		return err
+	}
+	own, err := lc.ownAddresses(ctx, client, maddr)
+	if err != nil {
+		return err
+	}
+	if len(own) == 0 {
+		return nil
+	}
+
+	head, err := client.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+	msgs, err := client.MpoolPending(ctx, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	baseFee := head.baseFee()
+	if baseFee != nil {
+		ch <- prometheus.MustNewConstMetric(lc.baseFeeDesc, prometheus.GaugeValue, bigToFloat(*baseFee))
+	}
+
+	type methodKey struct {
+		from, method string
+	}
+	counts := make(map[methodKey]int)
+	minFeeCap := make(map[string]float64)
+	minPremium := make(map[string]float64)
+	below := make(map[string]int)
+	oldest := make(map[string]time.Time)
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	now := time.Now()
+	seen := make(map[messageKey]time.Time)
+	for _, m := range msgs {
+		from, ok := own[m.Message.From]
+		if !ok {
+			continue
+		}
+		counts[methodKey{from: from, method: methodName(m, maddr)}]++
+
+		key := messageKey{from: m.Message.From, nonce: m.Message.Nonce}
+		first, ok := lc.firstSeen[key]
+		if !ok {
+			first = now
+		}
+		seen[key] = first
+		if o, ok := oldest[from]; !ok || first.Before(o) {
+			oldest[from] = first
+		}
+
+		if fc := m.feeCap(); fc != nil {
+			v := bigToFloat(*fc)
+			if min, ok := minFeeCap[from]; !ok || v < min {
+				minFeeCap[from] = v
+			}
+			if baseFee != nil && types.BigCmp(*fc, *baseFee) < 0 {
+				below[from]++
+			}
+		}
+		if m.Message.GasPremium != nil {
+			v := bigToFloat(*m.Message.GasPremium)
+			if min, ok := minPremium[from]; !ok || v < min {
+				minPremium[from] = v
+			}
+		}
+	}
+	// 已上链或被丢弃的消息不再记录
+	lc.firstSeen = seen
+
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(lc.pending, prometheus.GaugeValue, float64(count), k.from, k.method)
+	}
+	for from, first := range oldest {
+		ch <- prometheus.MustNewConstMetric(lc.oldest, prometheus.GaugeValue, now.Sub(first).Seconds(), from)
+		if baseFee != nil {
+			ch <- prometheus.MustNewConstMetric(lc.belowBase, prometheus.GaugeValue, float64(below[from]), from)
+		}
+	}
+	for from, v := range minFeeCap {
+		ch <- prometheus.MustNewConstMetric(lc.feeCap, prometheus.GaugeValue, v, from)
+	}
+	for from, v := range minPremium {
+		ch <- prometheus.MustNewConstMetric(lc.premium, prometheus.GaugeValue, v, from)
+	}
+	return nil
+}
+
+// 需要监控的地址，消息的 From 为公钥地址，ID 地址需要转换后才能匹配，值为指标中使用的地址
+func (lc *lotusMpoolCollector) ownAddresses(ctx context.Context, client *Client, maddr address.Address) (map[address.Address]string, error) {
+	addrs := append([]address.Address{}, lc.addrs...)
+	if maddr != address.Undef {
+		info, err := client.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, info.Owner, info.Worker)
+		addrs = append(addrs, info.ControlAddresses...)
+	}
+
+	own := make(map[address.Address]string)
+	for _, addr := range addrs {
+		key := addr
+		if addr.Protocol() == address.ID {
+			k, err := client.StateAccountKey(ctx, addr, types.EmptyTSK)
+			if err != nil {
+				return nil, fmt.Errorf("get account key of %s: %w", addr, err)
+			}
+			key = k
+		}
+		own[key] = key.String()
+		own[addr] = key.String()
+	}
+	return own, nil
+}
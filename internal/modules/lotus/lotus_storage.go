@@ -0,0 +1,154 @@
+package lotus
+
+import (
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"github.com/filecoin-project/sector-storage/stores"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+// 与 stores.StorageInfo 的 JSON 一致，HeartbeatErr 为 error 类型无法解析，不读取
+type storageInfo struct {
+	ID            stores.ID
+	URLs          []string
+	Weight        uint64
+	CanSeal       bool
+	CanStore      bool
+	LastHeartbeat time.Time
+}
+
+// 与 stores.FsStat 的 JSON 一致，Reserved 只有新版本 lotus 返回
+type storageStat struct {
+	Capacity  int64
+	Available int64
+	Used      int64
+	Reserved  *int64
+}
+
+type lotusStorageCollector struct {
+	info      *prometheus.Desc
+	weight    *prometheus.Desc
+	capacity  *prometheus.Desc
+	available *prometheus.Desc
+	used      *prometheus.Desc
+	reserved  *prometheus.Desc
+	sectors   *prometheus.Desc
+	heartbeat *prometheus.Desc
+
+	ep     endpoint
+	logger log.Logger
+}
+
+func init() {
+	registerCollector("lotus-storage", minerEndpoint, defaultEnabled, NewLotusStorageCollector)
+}
+
+func NewLotusStorageCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	return &lotusStorageCollector{
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "info"),
+			"lotus storage path, local path or first url for paths on workers.",
+			[]string{"id", "path", "can_seal", "can_store"}, ep.labels(),
+		),
+		weight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "weight"),
+			"lotus storage path weight.",
+			[]string{"id"}, ep.labels(),
+		),
+		capacity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "capacity_bytes"),
+			"lotus storage path capacity.",
+			[]string{"id"}, ep.labels(),
+		),
+		available: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "available_bytes"),
+			"lotus storage path space available for sectors.",
+			[]string{"id"}, ep.labels(),
+		),
+		used: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "used_bytes"),
+			"lotus storage path space used by sectors.",
+			[]string{"id"}, ep.labels(),
+		),
+		reserved: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "reserved_bytes"),
+			"lotus storage path space reserved by running tasks, only reported by newer lotus.",
+			[]string{"id"}, ep.labels(),
+		),
+		sectors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "sectors"),
+			"lotus sector files per storage path by file type.",
+			[]string{"id", "type"}, ep.labels(),
+		),
+		heartbeat: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "storage", "heartbeat_age_seconds"),
+			"Seconds since the last heartbeat of the storage path.",
+			[]string{"id"}, ep.labels(),
+		),
+		ep:     ep,
+		logger: logger,
+	}, nil
+}
+
+func (lc *lotusStorageCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.miner.context()
+	defer cancel()
+	minerClient, err := lc.ep.miner.miner()
+	if err != nil {
+		return err
+	}
+
+	list, err := minerClient.StorageList(ctx)
+	if err != nil {
+		return err
+	}
+	local, err := minerClient.StorageLocal(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for id, decls := range list {
+		sid := string(id)
+		counts := make(map[stores.SectorFileType]int)
+		for _, d := range decls {
+			counts[d.SectorFileType]++
+		}
+		for ft, count := range counts {
+			ch <- prometheus.MustNewConstMetric(lc.sectors, prometheus.GaugeValue, float64(count), sid, ft.String())
+		}
+
+		// worker 的存储路径可能已断开，不影响其他路径
+		info, err := minerClient.StorageInfo(ctx, id)
+		if err != nil {
+			lc.logger.Debugf("get storage %s info err: %v", id, err)
+			continue
+		}
+		path, ok := local[id]
+		if !ok && len(info.URLs) > 0 {
+			path = info.URLs[0]
+		}
+		ch <- prometheus.MustNewConstMetric(lc.info, prometheus.GaugeValue, 1, sid, path,
+			strconv.FormatBool(info.CanSeal), strconv.FormatBool(info.CanStore))
+		ch <- prometheus.MustNewConstMetric(lc.weight, prometheus.GaugeValue, float64(info.Weight), sid)
+		if !info.LastHeartbeat.IsZero() {
+			ch <- prometheus.MustNewConstMetric(lc.heartbeat, prometheus.GaugeValue, now.Sub(info.LastHeartbeat).Seconds(), sid)
+		}
+
+		st, err := minerClient.StorageStat(ctx, id)
+		if err != nil {
+			lc.logger.Debugf("get storage %s stat err: %v", id, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(lc.capacity, prometheus.GaugeValue, float64(st.Capacity), sid)
+		ch <- prometheus.MustNewConstMetric(lc.available, prometheus.GaugeValue, float64(st.Available), sid)
+		ch <- prometheus.MustNewConstMetric(lc.used, prometheus.GaugeValue, float64(st.Used), sid)
+		if st.Reserved != nil {
+			ch <- prometheus.MustNewConstMetric(lc.reserved, prometheus.GaugeValue, float64(*st.Reserved), sid)
+		}
+	}
+	return nil
+}
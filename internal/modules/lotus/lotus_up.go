@@ -0,0 +1,39 @@
+package lotus
+
+import (
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 每个 lotus 连接一个，服务不可用时指标为 0 而不是缺失
+type lotusUpCollector struct {
+	up     *prometheus.Desc
+	conn   *rpcConn
+	logger log.Logger
+}
+
+func newLotusUpCollector(logger log.Logger, conn *rpcConn) gateway.Collector {
+	return &lotusUpCollector{
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "up"),
+			"Whether the lotus api answered the last Version call.",
+			[]string{"type"}, endpoint{name: conn.name}.labels(),
+		),
+		conn:   conn,
+		logger: logger,
+	}
+}
+
+func (lc *lotusUpCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.conn.context()
+	defer cancel()
+	var up float64
+	if err := lc.conn.ping(ctx); err != nil {
+		lc.logger.Debugf("lotus %s %s is down: %v", lc.conn.kind, lc.conn.name, err)
+	} else {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(lc.up, prometheus.GaugeValue, up, lc.conn.kind)
+	return nil
+}
@@ -0,0 +1,167 @@
+package lotus
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 与 miner.MinerInfo 的 JSON 一致，ControlAddresses 只有新版本 lotus 返回
+type minerInfo struct {
+	Owner                      address.Address
+	Worker                     address.Address
+	ControlAddresses           []address.Address
+	WindowPoStPartitionSectors uint64
+}
+
+// StateReadState 返回的 miner actor 状态，只解析资金相关字段
+type minerActorState struct {
+	Balance types.BigInt
+	State   struct {
+		PreCommitDeposits types.BigInt
+		LockedFunds       types.BigInt
+		// lotus v0.4.1 没有质押要求字段，新版本 lotus 先后使用这两个名称
+		InitialPledgeRequirement *types.BigInt
+		InitialPledge            *types.BigInt
+		// lotus v0.4.1 按分区号记录本证明周期已提交 PoSt 的分区
+		PostSubmissions *abi.BitField
+	}
+}
+
+func (s *minerActorState) initialPledge() *types.BigInt {
+	if s.State.InitialPledge != nil {
+		return s.State.InitialPledge
+	}
+	return s.State.InitialPledgeRequirement
+}
+
+const (
+	roleOwner   = "owner"
+	roleWorker  = "worker"
+	roleControl = "control"
+	roleWallet  = "wallet"
+)
+
+type lotusWalletCollector struct {
+	balance *prometheus.Desc
+	funds   *prometheus.Desc
+
+	wallet []address.Address
+	maddr  address.Address
+	ep     endpoint
+}
+
+type lotusWalletOptions struct {
+	// 需要监控余额的钱包地址
+	Addresses   []string `mapstructure:"addresses"`
+	minerOption `mapstructure:",squash"`
+}
+
+func init() {
+	registerCollector("lotus-wallet", chainEndpoint, defaultEnabled, NewLotusWalletCollector)
+}
+
+func NewLotusWalletCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	var opts lotusWalletOptions
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-wallet options: %w", err)
+	}
+
+	wallet, err := parseAddresses(opts.Addresses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lotus-wallet addresses: %w", err)
+	}
+	maddr, err := opts.minerAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lotusWalletCollector{
+		balance: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wallet", "balance"),
+			"Wallet balance in attoFIL by address role.",
+			[]string{"address", "role"}, ep.labels(),
+		),
+		funds: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "miner", "funds"),
+			"lotus miner actor funds in attoFIL.",
+			[]string{"miner", "type"}, ep.labels(),
+		),
+		wallet: wallet,
+		maddr:  maddr,
+		ep:     ep,
+	}, nil
+}
+
+func (lc *lotusWalletCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.daemon.context()
+	defer cancel()
+	client, err := lc.ep.daemon.daemon()
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range lc.wallet {
+		if err := lc.updateBalance(ctx, client, ch, addr, roleWallet); err != nil {
+			return err
+		}
+	}
+
+	maddr, err := lc.ep.minerAddress(ctx, lc.maddr)
+	if err != nil || maddr == address.Undef {
+		return err
+	}
+	m := maddr.String()
+
+	info, err := client.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	if err := lc.updateBalance(ctx, client, ch, info.Owner, roleOwner); err != nil {
+		return err
+	}
+	if info.Worker != info.Owner {
+		if err := lc.updateBalance(ctx, client, ch, info.Worker, roleWorker); err != nil {
+			return err
+		}
+	}
+	for _, addr := range info.ControlAddresses {
+		if err := lc.updateBalance(ctx, client, ch, addr, roleControl); err != nil {
+			return err
+		}
+	}
+
+	available, err := client.StateMinerAvailableBalance(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	var state minerActorState
+	if err := readState(ctx, client, maddr, &state); err != nil {
+		return err
+	}
+	total := bigToFloat(state.Balance)
+	ch <- prometheus.MustNewConstMetric(lc.funds, prometheus.GaugeValue, total, m, "total")
+	ch <- prometheus.MustNewConstMetric(lc.funds, prometheus.GaugeValue, bigToFloat(available), m, "available")
+	ch <- prometheus.MustNewConstMetric(lc.funds, prometheus.GaugeValue, total-bigToFloat(available), m, "locked")
+	ch <- prometheus.MustNewConstMetric(lc.funds, prometheus.GaugeValue, bigToFloat(state.State.LockedFunds), m, "vesting")
+	ch <- prometheus.MustNewConstMetric(lc.funds, prometheus.GaugeValue, bigToFloat(state.State.PreCommitDeposits), m, "precommit_deposits")
+	if pledge := state.initialPledge(); pledge != nil {
+		ch <- prometheus.MustNewConstMetric(lc.funds, prometheus.GaugeValue, bigToFloat(*pledge), m, "initial_pledge")
+	}
+	return nil
+}
+
+func (lc *lotusWalletCollector) updateBalance(ctx context.Context, client *Client, ch chan<- prometheus.Metric, addr address.Address, role string) error {
+	balance, err := client.WalletBalance(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("get %s balance: %w", addr, err)
+	}
+	ch <- prometheus.MustNewConstMetric(lc.balance, prometheus.GaugeValue, bigToFloat(balance), addr.String(), role)
+	return nil
+}
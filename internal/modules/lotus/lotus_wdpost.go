@@ -0,0 +1,307 @@
+package lotus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+// 新版本 lotus StateMinerPartitions 返回的分区
+type minerPartition struct {
+	AllSectors        bitfield.BitField
+	FaultySectors     bitfield.BitField
+	RecoveringSectors bitfield.BitField
+	ActiveSectors     bitfield.BitField
+}
+
+// 新版本 lotus StateMinerDeadlines 返回的 deadline
+type minerDeadline struct {
+	PostSubmissions bitfield.BitField
+}
+
+// 单个 deadline 的分区和扇区数
+type deadlineStats struct {
+	partitions uint64
+	all        uint64
+	faulty     uint64
+	recovering uint64
+	active     uint64
+}
+
+type lotusWdpostCollector struct {
+	deadline     *prometheus.Desc
+	open         *prometheus.Desc
+	close        *prometheus.Desc
+	periodStart  *prometheus.Desc
+	nextDeadline *prometheus.Desc
+	partitions   *prometheus.Desc
+	sectors      *prometheus.Desc
+	submitted    *prometheus.Desc
+
+	opts  lotusWdpostOptions
+	maddr address.Address
+	ep    endpoint
+}
+
+type lotusWdpostOptions struct {
+	minerOption `mapstructure:",squash"`
+	// 出块间隔，用于计算距下一个 deadline 的时间
+	BlockDelay time.Duration `mapstructure:"block-delay"`
+}
+
+func init() {
+	registerCollector("lotus-wdpost", chainEndpoint, defaultEnabled, NewLotusWdpostCollector)
+}
+
+func NewLotusWdpostCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	opts := lotusWdpostOptions{BlockDelay: builtin.EpochDurationSeconds * time.Second}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid lotus-wdpost options: %w", err)
+	}
+	maddr, err := opts.minerAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lotusWdpostCollector{
+		deadline: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "deadline_index"),
+			"Current WindowPoSt deadline index.",
+			[]string{"miner"}, ep.labels(),
+		),
+		open: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "deadline_open_epoch"),
+			"First epoch in which a proof may be submitted for the current deadline.",
+			[]string{"miner"}, ep.labels(),
+		),
+		close: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "deadline_close_epoch"),
+			"First epoch in which a proof may no longer be submitted for the current deadline.",
+			[]string{"miner"}, ep.labels(),
+		),
+		periodStart: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "period_start_epoch"),
+			"First epoch of the current proving period.",
+			[]string{"miner"}, ep.labels(),
+		),
+		nextDeadline: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "next_deadline_seconds"),
+			"Seconds until the current deadline closes and the next one opens.",
+			[]string{"miner"}, ep.labels(),
+		),
+		partitions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "partitions"),
+			"Partitions per deadline.",
+			[]string{"miner", "deadline"}, ep.labels(),
+		),
+		sectors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "sectors"),
+			"Sectors per deadline by state.",
+			[]string{"miner", "deadline", "state"}, ep.labels(),
+		),
+		submitted: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "wdpost", "submitted"),
+			"Whether PoSt was submitted for all partitions of the current deadline, 0 when the deadline has no partitions.",
+			[]string{"miner"}, ep.labels(),
+		),
+		opts:  opts,
+		maddr: maddr,
+		ep:    ep,
+	}, nil
+}
+
+func (lc *lotusWdpostCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.daemon.context()
+	defer cancel()
+	client, err := lc.ep.daemon.daemon()
+	if err != nil {
+		return err
+	}
+	maddr, err := lc.ep.minerAddress(ctx, lc.maddr)
+	if err != nil || maddr == address.Undef {
+		return err
+	}
+	m := maddr.String()
+
+	dl, err := client.StateMinerProvingDeadline(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	next := time.Duration(dl.Close-dl.CurrentEpoch) * lc.opts.BlockDelay
+	ch <- prometheus.MustNewConstMetric(lc.deadline, prometheus.GaugeValue, float64(dl.Index), m)
+	ch <- prometheus.MustNewConstMetric(lc.open, prometheus.GaugeValue, float64(dl.Open), m)
+	ch <- prometheus.MustNewConstMetric(lc.close, prometheus.GaugeValue, float64(dl.Close), m)
+	ch <- prometheus.MustNewConstMetric(lc.periodStart, prometheus.GaugeValue, float64(dl.PeriodStart), m)
+	ch <- prometheus.MustNewConstMetric(lc.nextDeadline, prometheus.GaugeValue, next.Seconds(), m)
+
+	raw, err := client.StateMinerDeadlines(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	// lotus v0.4.1 返回按 deadline 分组的扇区，新版本 lotus 返回 deadline 数组，分区需要单独查询
+	var stats []deadlineStats
+	var submitted bool
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		stats, submitted, err = partitionStats(ctx, client, maddr, raw, dl.Index)
+	} else {
+		stats, submitted, err = dueStats(ctx, client, maddr, raw, dl.Index)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, s := range stats {
+		idx := strconv.Itoa(i)
+		ch <- prometheus.MustNewConstMetric(lc.partitions, prometheus.GaugeValue, float64(s.partitions), m, idx)
+		ch <- prometheus.MustNewConstMetric(lc.sectors, prometheus.GaugeValue, float64(s.all), m, idx, "all")
+		ch <- prometheus.MustNewConstMetric(lc.sectors, prometheus.GaugeValue, float64(s.faulty), m, idx, "faulty")
+		ch <- prometheus.MustNewConstMetric(lc.sectors, prometheus.GaugeValue, float64(s.recovering), m, idx, "recovering")
+		ch <- prometheus.MustNewConstMetric(lc.sectors, prometheus.GaugeValue, float64(s.active), m, idx, "active")
+	}
+	ch <- prometheus.MustNewConstMetric(lc.submitted, prometheus.GaugeValue, boolToFloat(submitted), m)
+	return nil
+}
+
+// lotus v0.4.1：按分区大小划分每个 deadline 的扇区，错误和恢复中的扇区取与全局位图的交集
+func dueStats(ctx context.Context, client *Client, maddr address.Address, raw json.RawMessage, current uint64) ([]deadlineStats, bool, error) {
+	var deadlines miner.Deadlines
+	if err := json.Unmarshal(raw, &deadlines); err != nil {
+		return nil, false, fmt.Errorf("decode miner deadlines: %w", err)
+	}
+	for i := range deadlines.Due {
+		if deadlines.Due[i] == nil {
+			deadlines.Due[i] = abi.NewBitField()
+		}
+	}
+	info, err := client.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return nil, false, err
+	}
+	if info.WindowPoStPartitionSectors == 0 {
+		return nil, false, fmt.Errorf("miner %s has no partition size", maddr)
+	}
+	faults, err := client.StateMinerFaults(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return nil, false, err
+	}
+	recoveries, err := client.StateMinerRecoveries(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return nil, false, err
+	}
+	var state minerActorState
+	if err := readState(ctx, client, maddr, &state); err != nil {
+		return nil, false, err
+	}
+
+	stats := make([]deadlineStats, len(deadlines.Due))
+	for i, due := range deadlines.Due {
+		partitions, all, err := miner.DeadlineCount(&deadlines, info.WindowPoStPartitionSectors, uint64(i))
+		if err != nil {
+			return nil, false, err
+		}
+		faulty, err := intersectCount(due, faults)
+		if err != nil {
+			return nil, false, err
+		}
+		recovering, err := intersectCount(due, recoveries)
+		if err != nil {
+			return nil, false, err
+		}
+		stats[i] = deadlineStats{
+			partitions: partitions,
+			all:        all,
+			faulty:     faulty,
+			recovering: recovering,
+			active:     all - faulty,
+		}
+	}
+
+	if current >= uint64(len(stats)) {
+		return stats, false, nil
+	}
+	first, _, err := miner.PartitionsForDeadline(&deadlines, info.WindowPoStPartitionSectors, current)
+	if err != nil {
+		return nil, false, err
+	}
+	// 没有分区的 deadline 不需要提交证明，记为未提交
+	submitted := stats[current].partitions > 0
+	for p := first; p < first+stats[current].partitions; p++ {
+		ok := false
+		if state.State.PostSubmissions != nil {
+			if ok, err = state.State.PostSubmissions.IsSet(p); err != nil {
+				return nil, false, err
+			}
+		}
+		submitted = submitted && ok
+	}
+	return stats, submitted, nil
+}
+
+// 新版本 lotus：逐个 deadline 查询分区
+func partitionStats(ctx context.Context, client *Client, maddr address.Address, raw json.RawMessage, current uint64) ([]deadlineStats, bool, error) {
+	var deadlines []minerDeadline
+	if err := json.Unmarshal(raw, &deadlines); err != nil {
+		return nil, false, fmt.Errorf("decode miner deadlines: %w", err)
+	}
+
+	var submitted bool
+	stats := make([]deadlineStats, len(deadlines))
+	for i := range deadlines {
+		partitions, err := client.StateMinerPartitions(ctx, maddr, uint64(i), types.EmptyTSK)
+		if err != nil {
+			return nil, false, err
+		}
+		s := deadlineStats{partitions: uint64(len(partitions))}
+		for _, p := range partitions {
+			for _, c := range []struct {
+				bf  bitfield.BitField
+				dst *uint64
+			}{
+				{p.AllSectors, &s.all},
+				{p.FaultySectors, &s.faulty},
+				{p.RecoveringSectors, &s.recovering},
+				{p.ActiveSectors, &s.active},
+			} {
+				n, err := c.bf.Count()
+				if err != nil {
+					return nil, false, err
+				}
+				*c.dst += n
+			}
+		}
+		stats[i] = s
+
+		if uint64(i) == current {
+			n, err := deadlines[i].PostSubmissions.Count()
+			if err != nil {
+				return nil, false, err
+			}
+			submitted = s.partitions > 0 && n >= s.partitions
+		}
+	}
+	return stats, submitted, nil
+}
+
+func intersectCount(a, b *abi.BitField) (uint64, error) {
+	if a == nil || b == nil {
+		return 0, nil
+	}
+	bf, err := bitfield.IntersectBitField(a, b)
+	if err != nil {
+		return 0, err
+	}
+	return bf.Count()
+}
@@ -0,0 +1,204 @@
+package lotus
+
+import (
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/gateway"
+	"fildr-cli/internal/log"
+	"github.com/filecoin-project/sector-storage/sealtasks"
+	"github.com/filecoin-project/sector-storage/storiface"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+// 与 storiface.WorkerStats 一致，Enabled 只有新版本 lotus 返回
+type workerStats struct {
+	Info    storiface.WorkerInfo
+	Enabled *bool
+
+	MemUsedMin uint64
+	MemUsedMax uint64
+	GpuUsed    bool
+	CpuUse     uint64
+}
+
+// 新版本 lotus WorkerJobs 返回的任务，RunWait 为 0 表示正在执行，大于 0 表示排队中
+type workerJob struct {
+	Sector  abi.SectorID
+	Task    sealtasks.TaskType
+	RunWait int
+	Start   time.Time
+}
+
+var taskShortNames = map[sealtasks.TaskType]string{
+	sealtasks.TTAddPiece:     "AP",
+	sealtasks.TTPreCommit1:   "PC1",
+	sealtasks.TTPreCommit2:   "PC2",
+	sealtasks.TTCommit1:      "C1",
+	sealtasks.TTCommit2:      "C2",
+	sealtasks.TTFinalize:     "FIN",
+	sealtasks.TTFetch:        "GET",
+	sealtasks.TTUnseal:       "UNS",
+	sealtasks.TTReadUnsealed: "RD",
+}
+
+func taskShortName(task sealtasks.TaskType) string {
+	if name, ok := taskShortNames[task]; ok {
+		return name
+	}
+	return string(task)
+}
+
+type lotusWorkerCollector struct {
+	resources *prometheus.Desc
+	gpus      *prometheus.Desc
+	memUsed   *prometheus.Desc
+	cpuUsed   *prometheus.Desc
+	gpuUsed   *prometheus.Desc
+	enabled   *prometheus.Desc
+	jobs      *prometheus.Desc
+	jobAge    *prometheus.Desc
+
+	ep     endpoint
+	logger log.Logger
+
+	// miner 不支持 WorkerJobs 时只提示一次
+	jobsOnce sync.Once
+}
+
+func init() {
+	registerCollector("lotus-worker", minerEndpoint, defaultEnabled, NewLotusWorkerCollector)
+}
+
+func NewLotusWorkerCollector(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error) {
+	labels := []string{"worker", "hostname"}
+	return &lotusWorkerCollector{
+		resources: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "resources"),
+			"lotus worker resources, memory in bytes and cpu in logical cores.",
+			append(labels, "resource"), ep.labels(),
+		),
+		gpus: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "gpu_info"),
+			"lotus worker gpus.",
+			append(labels, "gpu"), ep.labels(),
+		),
+		memUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "mem_reserved_bytes"),
+			"lotus worker memory reserved by running tasks.",
+			append(labels, "type"), ep.labels(),
+		),
+		cpuUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "cpu_reserved"),
+			"lotus worker cpu cores reserved by running tasks.",
+			labels, ep.labels(),
+		),
+		gpuUsed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "gpu_reserved"),
+			"Whether lotus worker gpu is reserved by a running task.",
+			labels, ep.labels(),
+		),
+		enabled: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "enabled"),
+			"Whether lotus worker is enabled, only reported by newer lotus.",
+			labels, ep.labels(),
+		),
+		jobs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "jobs"),
+			"lotus worker jobs by task type and state.",
+			append(labels, "task", "state"), ep.labels(),
+		),
+		jobAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "worker", "job_oldest_seconds"),
+			"Age of the oldest lotus worker job by task type and state.",
+			append(labels, "task", "state"), ep.labels(),
+		),
+		ep:     ep,
+		logger: logger,
+	}, nil
+}
+
+func (lc *lotusWorkerCollector) Update(ch chan<- prometheus.Metric) error {
+	ctx, cancel := lc.ep.miner.context()
+	defer cancel()
+	minerClient, err := lc.ep.miner.miner()
+	if err != nil {
+		return err
+	}
+
+	stats, err := minerClient.WorkerStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	hostnames := make(map[string]string)
+	for id, st := range stats {
+		host := st.Info.Hostname
+		hostnames[id] = host
+		res := st.Info.Resources
+
+		ch <- prometheus.MustNewConstMetric(lc.resources, prometheus.GaugeValue, float64(res.MemPhysical), id, host, "mem_physical")
+		ch <- prometheus.MustNewConstMetric(lc.resources, prometheus.GaugeValue, float64(res.MemSwap), id, host, "mem_swap")
+		ch <- prometheus.MustNewConstMetric(lc.resources, prometheus.GaugeValue, float64(res.MemReserved), id, host, "mem_reserved")
+		ch <- prometheus.MustNewConstMetric(lc.resources, prometheus.GaugeValue, float64(res.CPUs), id, host, "cpus")
+		ch <- prometheus.MustNewConstMetric(lc.resources, prometheus.GaugeValue, float64(len(res.GPUs)), id, host, "gpus")
+		for _, gpu := range res.GPUs {
+			ch <- prometheus.MustNewConstMetric(lc.gpus, prometheus.GaugeValue, 1, id, host, gpu)
+		}
+
+		ch <- prometheus.MustNewConstMetric(lc.memUsed, prometheus.GaugeValue, float64(st.MemUsedMin), id, host, "min")
+		ch <- prometheus.MustNewConstMetric(lc.memUsed, prometheus.GaugeValue, float64(st.MemUsedMax), id, host, "max")
+		ch <- prometheus.MustNewConstMetric(lc.cpuUsed, prometheus.GaugeValue, float64(st.CpuUse), id, host)
+		ch <- prometheus.MustNewConstMetric(lc.gpuUsed, prometheus.GaugeValue, boolToFloat(st.GpuUsed), id, host)
+		if st.Enabled != nil {
+			ch <- prometheus.MustNewConstMetric(lc.enabled, prometheus.GaugeValue, boolToFloat(*st.Enabled), id, host)
+		}
+	}
+
+	jobs, err := minerClient.WorkerJobs(ctx)
+	if err != nil {
+		lc.jobsOnce.Do(func() {
+			lc.logger.Infof("lotus miner does not support WorkerJobs, worker jobs are not collected: %v", err)
+		})
+		return nil
+	}
+
+	type jobKey struct {
+		worker, task, state string
+	}
+	counts := make(map[jobKey]int)
+	oldest := make(map[jobKey]time.Time)
+	for id, list := range jobs {
+		for _, job := range list {
+			state := "running"
+			if job.RunWait != 0 {
+				state = "queued"
+			}
+			k := jobKey{worker: id, task: taskShortName(job.Task), state: state}
+			counts[k]++
+			if o, ok := oldest[k]; !ok || job.Start.Before(o) {
+				oldest[k] = job.Start
+			}
+		}
+	}
+	now := time.Now()
+	for k, count := range counts {
+		host := hostnames[k.worker]
+		ch <- prometheus.MustNewConstMetric(lc.jobs, prometheus.GaugeValue, float64(count), k.worker, host, k.task, k.state)
+		age := 0.0
+		if start := oldest[k]; !start.IsZero() {
+			age = now.Sub(start).Seconds()
+		}
+		ch <- prometheus.MustNewConstMetric(lc.jobAge, prometheus.GaugeValue, age, k.worker, host, k.task, k.state)
+	}
+
+	return nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -2,24 +2,82 @@ package lotus
 
 import (
 	"context"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fildr-cli/internal/module"
+	"fmt"
+	"github.com/filecoin-project/go-address"
 )
 
 var _ module.Module = (*LotusCollectorModule)(nil)
 
+const (
+	defaultEnabled  = true
+	defaultDisabled = false
+)
+
+// 收集器依赖的 lotus 服务，每个服务启动一个收集器，对应服务未开启时不启动收集器
+const (
+	daemonEndpoint = "daemon"
+	minerEndpoint  = "miner"
+	// 矿工的链上数据，有 miner 时每个 miner 启动一个，否则每个 daemon 启动一个
+	chainEndpoint = "chain"
+)
+
+// 需要矿工地址的收集器共用的选项
+type minerOption struct {
+	// 矿工地址，未配置且开启 lotus.miner 时从 lotus-miner 获取
+	Miner string `mapstructure:"miner"`
+}
+
+// 解析配置的矿工地址，未配置时返回 address.Undef
+func (o minerOption) minerAddress() (address.Address, error) {
+	if o.Miner == "" {
+		return address.Undef, nil
+	}
+	maddr, err := address.NewFromString(o.Miner)
+	if err != nil {
+		return address.Undef, fmt.Errorf("invalid miner address %s: %w", o.Miner, err)
+	}
+	return maddr, nil
+}
+
+type factory func(logger log.Logger, cfg config.Collector, ep endpoint) (gateway.Collector, error)
+
 var (
-	namespace = "lotus"
-	factories = make(map[string]func(logger log.Logger) (gateway.Collector, error))
+	namespace         = "lotus"
+	factories         = make(map[string]factory)
+	collectorState    = make(map[string]bool)
+	collectorEndpoint = make(map[string]string)
 )
 
-func registerCollector(collector string, factory func(logger log.Logger) (gateway.Collector, error)) {
-	factories[collector] = factory
+func registerCollector(collector string, endpoint string, isDefaultEnabled bool, f factory) {
+	factories[collector] = f
+	collectorState[collector] = isDefaultEnabled
+	collectorEndpoint[collector] = endpoint
+}
+
+// 收集器需要启动的服务
+func collectorEndpoints(kind string, daemons, miners []endpoint) []endpoint {
+	switch kind {
+	case daemonEndpoint:
+		return daemons
+	case minerEndpoint:
+		return miners
+	case chainEndpoint:
+		if len(miners) > 0 {
+			return miners
+		}
+		return daemons
+	default:
+		return nil
+	}
 }
 
 type LotusCollectorModule struct {
 	logger log.Logger
+	conns  []*rpcConn
 }
 
 func New(ctx context.Context) (*LotusCollectorModule, error) {
@@ -32,17 +90,46 @@ func (mod *LotusCollectorModule) Name() string {
 }
 
 func (mod *LotusCollectorModule) Start() error {
+	cfg := config.Get()
+	daemons, miners, conns, err := sharedEndpoints()
+	if err != nil {
+		return err
+	}
+	// 收集器不在启动时连接 lotus，第一次采集时才连接，lotus 未启动不影响 agent 启动
+	mod.conns = conns
+	for _, conn := range conns {
+		// 同名的 daemon 和 miner 分开注册
+		name := endpoint{name: conn.name}.collectorName("lotus-up-" + conn.kind)
+		gateway.Registry("lotus", name, newLotusUpCollector(mod.logger, conn))
+	}
 	for k, c := range factories {
-		collector, err := c(mod.logger)
-		if err != nil {
-			mod.logger.Warnf("collector %s is err: %v", k, err)
+		collectorCfg := cfg.Collectors[k]
+		if !collectorCfg.Enabled(collectorState[k]) {
+			mod.logger.Debugf("collector %s is disabled", k)
 			continue
 		}
-		gateway.Registry("lotus", k, collector)
+		eps := collectorEndpoints(collectorEndpoint[k], daemons, miners)
+		if len(eps) == 0 {
+			mod.logger.Debugf("collector %s is skipped, lotus %s is disabled", k, collectorEndpoint[k])
+			continue
+		}
+		// 每个服务独立的收集器，一个服务出错不影响其他服务
+		for _, ep := range eps {
+			name := ep.collectorName(k)
+			collector, err := c(mod.logger, collectorCfg, ep)
+			if err != nil {
+				mod.logger.Warnf("collector %s is err: %v", name, err)
+				gateway.RegistryFailed("lotus", name)
+				continue
+			}
+			gateway.Registry("lotus", name, collector)
+		}
 	}
 	return nil
 }
 
 func (mod *LotusCollectorModule) Stop() {
-
+	for _, conn := range mod.conns {
+		conn.close()
+	}
 }
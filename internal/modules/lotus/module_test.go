@@ -0,0 +1,27 @@
+package lotus
+
+import (
+	"fildr-cli/internal/config"
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMinerOption(t *testing.T) {
+	var opts lotusWalletOptions
+	require.NoError(t, config.Collector{"miner": "t01000", "addresses": "t01001,t01002"}.Decode(&opts))
+	assert.Equal(t, []string{"t01001", "t01002"}, opts.Addresses)
+	maddr, err := opts.minerAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "t01000", maddr.String())
+
+	var blocks lotusBlocksOptions
+	require.NoError(t, config.Collector{}.Decode(&blocks))
+	maddr, err = blocks.minerAddress()
+	require.NoError(t, err)
+	assert.Equal(t, address.Undef, maddr)
+
+	_, err = minerOption{Miner: "bad"}.minerAddress()
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package lotus
+
+import (
+	"encoding/json"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"strings"
+)
+
+// 与 peer.AddrInfo 的 JSON 一致，地址按字符串读取，无法解析的地址不影响其他节点
+type netPeer struct {
+	ID    peer.ID
+	Addrs []string
+}
+
+// lotus v0.4.1 的 Score 为数值，新版本 lotus 为包含 Score 字段的对象
+type pubsubScore struct {
+	ID    peer.ID
+	Score json.RawMessage
+}
+
+func (s *pubsubScore) value() (float64, bool) {
+	var v *float64
+	if err := json.Unmarshal(s.Score, &v); err == nil && v != nil {
+		return *v, true
+	}
+	var snapshot struct {
+		Score *float64
+	}
+	if err := json.Unmarshal(s.Score, &snapshot); err == nil && snapshot.Score != nil {
+		return *snapshot.Score, true
+	}
+	return 0, false
+}
+
+// gossipsub 分数的直方图区间，负分表示节点被惩罚
+var peerScoreBuckets = []float64{-1000, -100, -10, -1, 0, 1, 10, 100, 1000}
+
+const unknownValue = "unknown"
+
+// 连接地址的网络层和传输层协议，例如 ip4 和 tcp，无法解析时为 unknown
+func peerTransport(addr string) (network, transport string) {
+	network, transport = unknownValue, unknownValue
+	m, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return network, transport
+	}
+	ma.ForEach(m, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_IP4, ma.P_IP6, ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+			network = c.Protocol().Name
+		case ma.P_TCP:
+			transport = "tcp"
+		case ma.P_UDP:
+			transport = "udp"
+		case ma.P_QUIC:
+			transport = "quic"
+		case ma.P_WS, ma.P_WSS:
+			transport = c.Protocol().Name
+		case ma.P_CIRCUIT:
+			transport = "relay"
+			return false
+		}
+		return true
+	})
+	return network, transport
+}
+
+// 去掉版本号中 + 之后的构建信息，例如 lotus-0.5.1+git.abc 记为 lotus-0.5.1，控制标签数量
+func agentName(agent string) string {
+	if agent == "" {
+		return unknownValue
+	}
+	if i := strings.Index(agent, "+"); i > 0 {
+		agent = agent[:i]
+	}
+	return agent
+}
@@ -0,0 +1,141 @@
+package lotus
+
+import (
+	"context"
+	"errors"
+	"fildr-cli/internal/log"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestPeerTransport(t *testing.T) {
+	cases := []struct {
+		addr      string
+		network   string
+		transport string
+	}{
+		{"/ip4/1.2.3.4/tcp/1347", "ip4", "tcp"},
+		{"/ip6/::1/tcp/1347", "ip6", "tcp"},
+		{"/ip4/1.2.3.4/udp/1347/quic", "ip4", "quic"},
+		{"/ip4/1.2.3.4/udp/1347", "ip4", "udp"},
+		{"/dns4/bootstrap.example.com/tcp/443/wss", "dns4", "wss"},
+		{"/dns6/bootstrap.example.com/tcp/80/ws", "dns6", "ws"},
+		{"/ip4/1.2.3.4/tcp/1347/p2p/QmYyQSo1c1Ym7orWxLYvCrM2EmxFTANf8wXmmE7DWjhx5N/p2p-circuit", "ip4", "relay"},
+		{"/p2p-circuit", unknownValue, "relay"},
+		// 旧代码按 / 分割取第 2 和第 4 段，以下地址会越界
+		{"/ip4/1.2.3.4", "ip4", unknownValue},
+		{"", unknownValue, unknownValue},
+		{"garbage", unknownValue, unknownValue},
+		{"/ip4/999.0.0.1/tcp/1", unknownValue, unknownValue},
+	}
+	for _, c := range cases {
+		network, transport := peerTransport(c.addr)
+		assert.Equal(t, c.network, network, c.addr)
+		assert.Equal(t, c.transport, transport, c.addr)
+	}
+}
+
+func TestAgentName(t *testing.T) {
+	assert.Equal(t, "lotus-0.5.1", agentName("lotus-0.5.1+git.abc"))
+	assert.Equal(t, "lotus-0.4.1", agentName("lotus-0.4.1"))
+	assert.Equal(t, unknownValue, agentName(""))
+}
+
+func TestPubsubScoreValue(t *testing.T) {
+	cases := []struct {
+		raw   string
+		value float64
+		ok    bool
+	}{
+		{"1.5", 1.5, true},
+		{`{"Score":-3,"Topics":{}}`, -3, true},
+		{"null", 0, false},
+		{`{"Topics":{}}`, 0, false},
+		{`"bad"`, 0, false},
+	}
+	for _, c := range cases {
+		v, ok := (&pubsubScore{Score: []byte(c.raw)}).value()
+		assert.Equal(t, c.ok, ok, c.raw)
+		assert.Equal(t, c.value, v, c.raw)
+	}
+}
+
+func testPeers(n int) []netPeer {
+	ps := make([]netPeer, n)
+	for i := range ps {
+		ps[i] = netPeer{ID: peer.ID("peer-" + strconv.Itoa(i)), Addrs: []string{"/ip4/1.2.3.4/tcp/" + strconv.Itoa(1000+i)}}
+	}
+	return ps
+}
+
+func TestLookupPeers(t *testing.T) {
+	var calls int
+	client := &Client{
+		NetAgentVersion: func(ctx context.Context, id peer.ID) (string, error) {
+			calls++
+			return "lotus-0.5.1+git.abc", nil
+		},
+		NetFindPeer: func(ctx context.Context, id peer.ID) (netPeer, error) {
+			// 偶数节点为主动连接
+			if n, _ := strconv.Atoi(string(id)[len("peer-"):]); n%2 == 0 {
+				return netPeer{ID: id, Addrs: []string{"/ip4/1.2.3.4/tcp/" + strconv.Itoa(1000+n)}}, nil
+			}
+			return netPeer{ID: id}, nil
+		},
+	}
+	lc := &lotusDaemonCollector{
+		logger: log.NopLogger(),
+		opts:   lotusDaemonOptions{PeerAgents: true, PeerDirection: true, MaxPeerLookups: 10},
+	}
+
+	l := lc.lookupPeers(context.Background(), client, testPeers(100))
+	assert.Equal(t, 10, calls)
+	assert.Equal(t, map[string]int{"lotus-0.5.1": 10}, l.agentCounts)
+	assert.Equal(t, map[string]int{"outbound": 5, "inbound": 5}, l.directions)
+	assert.Len(t, l.agents, 10)
+}
+
+func TestLookupPeersBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	client := &Client{
+		NetAgentVersion: func(ctx context.Context, id peer.ID) (string, error) {
+			calls++
+			if calls == 3 {
+				cancel()
+				return "", ctx.Err()
+			}
+			return "lotus-0.5.1", nil
+		},
+	}
+	lc := &lotusDaemonCollector{
+		logger: log.NopLogger(),
+		opts:   lotusDaemonOptions{PeerAgents: true},
+	}
+
+	// 超时后返回已查询到的结果
+	l := lc.lookupPeers(ctx, client, testPeers(100))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, map[string]int{"lotus-0.5.1": 2}, l.agentCounts)
+}
+
+func TestLookupPeersUnsupported(t *testing.T) {
+	var calls int
+	client := &Client{
+		NetAgentVersion: func(ctx context.Context, id peer.ID) (string, error) {
+			calls++
+			return "", errors.New("method 'Filecoin.NetAgentVersion' not found")
+		},
+	}
+	lc := &lotusDaemonCollector{
+		logger: log.NopLogger(),
+		opts:   lotusDaemonOptions{PeerAgents: true},
+	}
+
+	// 不支持的接口只调用一次
+	l := lc.lookupPeers(context.Background(), client, testPeers(100))
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, l.agentCounts)
+}
@@ -1,25 +1,88 @@
 package lotus
 
 import (
-	"fildr-cli/internal/config"
-	"github.com/filecoin-project/go-jsonrpc"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/sector-storage/stores"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
 	"github.com/libp2p/go-libp2p-core/peer"
-	"net/http"
-	"strconv"
 )
 
 type Client struct {
-	Version         func() (api.Version, error)
-	NetPeers        func() ([]peer.AddrInfo, error)
-	NetPubsubScores func() ([]api.PubsubScore, error)
+	Version         func(context.Context) (api.Version, error)
+	NetPeers        func(context.Context) ([]netPeer, error)
+	NetPubsubScores func(context.Context) ([]pubsubScore, error)
+	NetFindPeer     func(context.Context, peer.ID) (netPeer, error)
+	// lotus v0.4.1 没有该接口，新版本 lotus 才能获取到节点的客户端版本
+	NetAgentVersion        func(context.Context, peer.ID) (string, error)
+	ChainHead              func(context.Context) (*chainTipSet, error)
+	ChainGetGenesis        func(context.Context) (*chainTipSet, error)
+	ChainGetTipSetByHeight func(context.Context, abi.ChainEpoch, types.TipSetKey) (*chainTipSet, error)
+	SyncState              func(context.Context) (*api.SyncState, error)
+
+	StateMinerPower            func(context.Context, address.Address, types.TipSetKey) (*api.MinerPower, error)
+	StateMinerFaults           func(context.Context, address.Address, types.TipSetKey) (*abi.BitField, error)
+	StateMinerRecoveries       func(context.Context, address.Address, types.TipSetKey) (*abi.BitField, error)
+	StateMinerProvingDeadline  func(context.Context, address.Address, types.TipSetKey) (*miner.DeadlineInfo, error)
+	StateMinerDeadlines        func(context.Context, address.Address, types.TipSetKey) (json.RawMessage, error)
+	StateMinerPartitions       func(context.Context, address.Address, uint64, types.TipSetKey) ([]minerPartition, error)
+	StateMinerInfo             func(context.Context, address.Address, types.TipSetKey) (minerInfo, error)
+	StateMinerAvailableBalance func(context.Context, address.Address, types.TipSetKey) (types.BigInt, error)
+	StateReadState             func(context.Context, address.Address, types.TipSetKey) (json.RawMessage, error)
+	WalletBalance              func(context.Context, address.Address) (types.BigInt, error)
+	StateAccountKey            func(context.Context, address.Address, types.TipSetKey) (address.Address, error)
+	MinerGetBaseInfo           func(context.Context, address.Address, abi.ChainEpoch, types.TipSetKey) (*miningBaseInfo, error)
+	MpoolPending               func(context.Context, types.TipSetKey) ([]*pendingMessage, error)
+}
+
+// lotus-miner 接口
+type MinerClient struct {
+	Version         func(context.Context) (api.Version, error)
+	ActorAddress    func(context.Context) (address.Address, error)
+	ActorSectorSize func(context.Context, address.Address) (abi.SectorSize, error)
+	PledgeSector    func(context.Context) error
+	SectorsList     func(context.Context) ([]abi.SectorNumber, error)
+	SectorsStatus   func(context.Context, abi.SectorNumber) (api.SectorInfo, error)
+	WorkerStats     func(context.Context) (map[string]workerStats, error)
+	StorageList     func(context.Context) (map[stores.ID][]stores.Decl, error)
+	StorageLocal    func(context.Context) (map[stores.ID]string, error)
+	StorageInfo     func(context.Context, stores.ID) (storageInfo, error)
+	StorageStat     func(context.Context, stores.ID) (storageStat, error)
+
+	MarketGetAsk              func(context.Context) (*storageAsk, error)
+	MarketListIncompleteDeals func(context.Context) ([]minerDeal, error)
+	// lotus v0.4.1 没有以下接口，新版本 lotus 才能获取到检索订单和数据传输
+	MarketListRetrievalDeals func(context.Context) ([]retrievalDeal, error)
+	MarketListDataTransfers  func(context.Context) ([]dataTransfer, error)
+	// lotus v0.4.1 没有该接口，新版本 lotus 才能获取到任务
+	WorkerJobs func(context.Context) (map[string][]workerJob, error)
+}
+
+// 读取 actor 状态，不同版本 lotus 的状态字段不同，按需要的字段解析
+func readState(ctx context.Context, client *Client, addr address.Address, v interface{}) error {
+	raw, err := client.StateReadState(ctx, addr, types.EmptyTSK)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("decode %s state: %w", addr, err)
+	}
+	return nil
 }
 
-func InitClient(client *Client) (jsonrpc.ClientCloser, error) {
-	cfg := config.Get()
-	requestHeader := http.Header{}
-	requestHeader.Add("Content-Type", "application/json")
-	ip := cfg.Lotus.Daemon.Ip
-	port := cfg.Lotus.Daemon.Port
-	return jsonrpc.NewClient("ws://"+ip+":"+strconv.Itoa(port)+"/rpc/v0", "Filecoin", client, requestHeader)
+func parseAddresses(list []string) ([]address.Address, error) {
+	var addrs []address.Address
+	for _, s := range list {
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
 }
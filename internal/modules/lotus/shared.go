@@ -0,0 +1,75 @@
+package lotus
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"sync"
+	"time"
+)
+
+// 按全局配置生成的服务，收集器和其他模块共享同一组连接
+var shared struct {
+	once    sync.Once
+	daemons []endpoint
+	miners  []endpoint
+	conns   []*rpcConn
+	err     error
+}
+
+func sharedEndpoints() (daemons []endpoint, miners []endpoint, conns []*rpcConn, err error) {
+	shared.once.Do(func() {
+		shared.daemons, shared.miners, shared.conns, shared.err = endpoints(config.Get().Lotus)
+	})
+	return shared.daemons, shared.miners, shared.conns, shared.err
+}
+
+// 供其他模块使用的 miner 及其 daemon，连接与收集器共享，按需建立并在失败后退避重试
+type Miner struct {
+	ep endpoint
+}
+
+// 按名称查找 miner，名称为空时为第一个 miner
+func SharedMiner(name string) (*Miner, error) {
+	_, miners, _, err := sharedEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range miners {
+		if name == "" || ep.name == name {
+			return &Miner{ep: ep}, nil
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("lotus miner is not configured")
+	}
+	return nil, fmt.Errorf("lotus miner %s is not configured", name)
+}
+
+// 配置中的名称，只有 [lotus.miner] 时为空
+func (m *Miner) Name() string {
+	return m.ep.name
+}
+
+// 每次调用接口的超时时间
+func (m *Miner) Timeout() time.Duration {
+	return m.ep.miner.cfg.CallTimeout()
+}
+
+// 返回 miner 和 daemon 的客户端以及矿工地址
+func (m *Miner) Clients(ctx context.Context) (*MinerClient, *Client, address.Address, error) {
+	miner, err := m.ep.miner.miner()
+	if err != nil {
+		return nil, nil, address.Undef, err
+	}
+	client, err := m.ep.daemon.daemon()
+	if err != nil {
+		return nil, nil, address.Undef, err
+	}
+	maddr, err := m.ep.miner.actorAddress(ctx)
+	if err != nil {
+		return nil, nil, address.Undef, err
+	}
+	return miner, client, maddr, nil
+}
@@ -4,6 +4,7 @@ package node
 
 import (
 	"bufio"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -19,11 +20,11 @@ type arpCollector struct {
 }
 
 func init() {
-	registerCollector("cpu", NewARPCollector)
+	registerCollector("arp", defaultDisabled, NewARPCollector)
 }
 
 // NewARPCollector returns a new Collector exposing ARP stats.
-func NewARPCollector(logger log.Logger) (gateway.Collector, error) {
+func NewARPCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &arpCollector{
 		entries: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "arp", "entries"),
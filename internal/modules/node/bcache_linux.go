@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -11,7 +12,7 @@ import (
 )
 
 func init() {
-	registerCollector("cpu", NewBcacheCollector)
+	registerCollector("bcache", defaultDisabled, NewBcacheCollector)
 }
 
 // A bcacheCollector is a Collector which gathers metrics from Linux bcache.
@@ -22,7 +23,7 @@ type bcacheCollector struct {
 
 // NewBcacheCollector returns a newly allocated bcacheCollector.
 // It exposes a number of Linux bcache statistics.
-func NewBcacheCollector(logger log.Logger) (gateway.Collector, error) {
+func NewBcacheCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := bcache.NewFS(sysPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sysfs: %w", err)
@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -20,12 +21,12 @@ type bondingCollector struct {
 }
 
 func init() {
-	registerCollector("bonding", NewBondingCollector)
+	registerCollector("bonding", defaultEnabled, NewBondingCollector)
 }
 
 // NewBondingCollector returns a newly allocated bondingCollector.
 // It exposes the number of configured and active slave of linux bonding interfaces.
-func NewBondingCollector(logger log.Logger) (gateway.Collector, error) {
+func NewBondingCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &bondingCollector{
 		slaves: gateway.TypedDesc{prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "bonding", "slaves"),
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -17,11 +18,11 @@ type btrfsCollector struct {
 }
 
 func init() {
-	registerCollector("btrfs", NewBtrfsCollector)
+	registerCollector("btrfs", defaultEnabled, NewBtrfsCollector)
 }
 
 // NewBtrfsCollector returns a new Collector exposing Btrfs statistics.
-func NewBtrfsCollector(logger log.Logger) (gateway.Collector, error) {
+func NewBtrfsCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := btrfs.NewFS(sysPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sysfs: %w", err)
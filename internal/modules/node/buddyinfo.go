@@ -4,6 +4,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -23,11 +24,11 @@ type buddyinfoCollector struct {
 }
 
 func init() {
-	//registerCollector("buddyinfo", NewBuddyinfoCollector)
+	registerCollector("buddyinfo", defaultDisabled, NewBuddyinfoCollector)
 }
 
 // NewBuddyinfoCollector returns a new Collector exposing buddyinfo stats.
-func NewBuddyinfoCollector(logger log.Logger) (gateway.Collector, error) {
+func NewBuddyinfoCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	desc := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, buddyInfoSubsystem, "blocks"),
 		"Count of free blocks according to size.",
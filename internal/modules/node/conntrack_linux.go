@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,11 +16,11 @@ type conntrackCollector struct {
 }
 
 func init() {
-	registerCollector("conntrack", NewConntrackCollector)
+	registerCollector("conntrack", defaultEnabled, NewConntrackCollector)
 }
 
 // NewConntrackCollector returns a new Collector exposing conntrack stats.
-func NewConntrackCollector(logger log.Logger) (gateway.Collector, error) {
+func NewConntrackCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &conntrackCollector{
 		current: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "nf_conntrack_entries"),
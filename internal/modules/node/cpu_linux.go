@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -16,7 +17,7 @@ import (
 const cpuCollectorSubsystem = "cpu"
 
 func init() {
-	registerCollector("cpu", NewCpuCollector)
+	registerCollector("cpu", defaultEnabled, NewCpuCollector)
 }
 
 var (
@@ -39,7 +40,7 @@ type cpuCollector struct {
 	logger             log.Logger
 }
 
-func NewCpuCollector(logger log.Logger) (gateway.Collector, error) {
+func NewCpuCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -22,11 +23,11 @@ type cpuFreqCollector struct {
 }
 
 func init() {
-	registerCollector("cpufreq", NewCPUFreqCollector)
+	registerCollector("cpufreq", defaultEnabled, NewCPUFreqCollector)
 }
 
 // NewCPUFreqCollector returns a new Collector exposing kernel/system statistics.
-func NewCPUFreqCollector(logger log.Logger) (gateway.Collector, error) {
+func NewCPUFreqCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := sysfs.NewFS(sysPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sysfs: %w", err)
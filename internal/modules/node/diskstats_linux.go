@@ -4,6 +4,7 @@ package node
 
 import (
 	"bufio"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -72,10 +73,6 @@ var (
 	)
 )
 
-var (
-	ignoredDevices = "^(ram|loop|fd|(h|s|v|xv)d[a-z]|nvme\\d+n\\d+p)\\d+$"
-)
-
 type typedFactorDesc struct {
 	desc      *prometheus.Desc
 	valueType prometheus.ValueType
@@ -89,6 +86,11 @@ func (d *typedFactorDesc) mustNewConstMetric(value float64, labels ...string) pr
 	return prometheus.MustNewConstMetric(d.desc, d.valueType, value, labels...)
 }
 
+type diskstatsOptions struct {
+	// Regexp of devices to ignore for diskstats.
+	IgnoredDevices string `mapstructure:"ignored-devices"`
+}
+
 type diskstatsCollector struct {
 	ignoredDevicesPattern *regexp.Regexp
 	descs                 []typedFactorDesc
@@ -96,16 +98,27 @@ type diskstatsCollector struct {
 }
 
 func init() {
-	registerCollector("diskstats", NewDiskstatsCollector)
+	registerCollector("diskstats", defaultEnabled, NewDiskstatsCollector)
 }
 
 // NewDiskstatsCollector returns a new Collector exposing disk device stats.
 // Docs from https://www.kernel.org/doc/Documentation/iostats.txt
-func NewDiskstatsCollector(logger log.Logger) (gateway.Collector, error) {
+func NewDiskstatsCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	var diskLabelNames = []string{"device"}
 
+	opts := diskstatsOptions{
+		IgnoredDevices: "^(ram|loop|fd|(h|s|v|xv)d[a-z]|nvme\\d+n\\d+p)\\d+$",
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid diskstats options: %w", err)
+	}
+	ignoredDevicesPattern, err := regexp.Compile(opts.IgnoredDevices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid diskstats ignored-devices: %w", err)
+	}
+
 	return &diskstatsCollector{
-		ignoredDevicesPattern: regexp.MustCompile(ignoredDevices),
+		ignoredDevicesPattern: ignoredDevicesPattern,
 		descs: []typedFactorDesc{
 			{
 				desc: readsCompletedDesc, valueType: prometheus.CounterValue,
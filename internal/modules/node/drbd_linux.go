@@ -5,6 +5,7 @@ package node
 import (
 	"bufio"
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -68,10 +69,10 @@ type drbdCollector struct {
 }
 
 func init() {
-	//registerCollector("drbd", newDRBDCollector)
+	registerCollector("drbd", defaultDisabled, newDRBDCollector)
 }
 
-func newDRBDCollector(logger log.Logger) (gateway.Collector, error) {
+func newDRBDCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &drbdCollector{
 		numerical: map[string]drbdNumericalMetric{
 			"ns": newDRBDNumericalMetric(
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -29,11 +30,11 @@ type edacCollector struct {
 }
 
 func init() {
-	registerCollector("edac", NewEdacCollector)
+	registerCollector("edac", defaultEnabled, NewEdacCollector)
 }
 
 // NewEdacCollector returns a new Collector exposing edac stats.
-func NewEdacCollector(logger log.Logger) (gateway.Collector, error) {
+func NewEdacCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &edacCollector{
 		ceCount: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, edacSubsystem, "correctable_errors_total"),
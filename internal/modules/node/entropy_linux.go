@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -15,11 +16,11 @@ type entropyCollector struct {
 }
 
 func init() {
-	registerCollector("entropy", NewEntropyCollector)
+	registerCollector("entropy", defaultEnabled, NewEntropyCollector)
 }
 
 // NewEntropyCollector returns a new Collector exposing entropy stats.
-func NewEntropyCollector(logger log.Logger) (gateway.Collector, error) {
+func NewEntropyCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &entropyCollector{
 		entropyAvail: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "entropy_available_bits"),
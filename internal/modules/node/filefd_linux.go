@@ -4,6 +4,7 @@ package node
 
 import (
 	"bytes"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -22,11 +23,11 @@ type fileFDStatCollector struct {
 }
 
 func init() {
-	registerCollector(fileFDStatSubsystem, NewFileFDStatCollector)
+	registerCollector(fileFDStatSubsystem, defaultEnabled, NewFileFDStatCollector)
 }
 
 // NewFileFDStatCollector returns a new Collector exposing file-nr stats.
-func NewFileFDStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewFileFDStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &fileFDStatCollector{logger}, nil
 }
 
@@ -4,10 +4,13 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"regexp"
+	"time"
 )
 
 // Arch-dependent implementation must define:
@@ -17,15 +20,20 @@ import (
 // * filesystemCollector.GetStats
 
 var (
-	ignoredMountPoints = defIgnoredMountPoints
-	ignoredFSTypes     = defIgnoredFSTypes
-
 	filesystemLabelNames = []string{"device", "mountpoint", "fstype"}
 )
 
+type filesystemOptions struct {
+	IgnoredMountPoints string `mapstructure:"ignored-mount-points"`
+	IgnoredFSTypes     string `mapstructure:"ignored-fs-types"`
+	// How long to wait for a mount to respond before marking it as stale.
+	MountTimeout time.Duration `mapstructure:"mount-timeout"`
+}
+
 type filesystemCollector struct {
 	ignoredMountPointsPattern     *regexp.Regexp
 	ignoredFSTypesPattern         *regexp.Regexp
+	mountTimeout                  time.Duration
 	sizeDesc, freeDesc, availDesc *prometheus.Desc
 	filesDesc, filesFreeDesc      *prometheus.Desc
 	roDesc, deviceErrorDesc       *prometheus.Desc
@@ -44,16 +52,31 @@ type filesystemStats struct {
 }
 
 func init() {
-	registerCollector("filesystem", NewFilesystemCollector)
+	registerCollector("filesystem", defaultEnabled, NewFilesystemCollector)
 }
 
 // NewFilesystemCollector returns a new Collector exposing filesystems stats.
-func NewFilesystemCollector(logger log.Logger) (gateway.Collector, error) {
+func NewFilesystemCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	subsystem := "filesystem"
-	logger.Infof("msg", "Parsed flag --collector.filesystem.ignored-mount-points", "flag", ignoredMountPoints)
-	mountPointPattern := regexp.MustCompile(ignoredMountPoints)
-	logger.Infof("msg", "Parsed flag --collector.filesystem.ignored-fs-types", "flag", ignoredMountPoints)
-	filesystemsTypesPattern := regexp.MustCompile(ignoredFSTypes)
+	opts := filesystemOptions{
+		IgnoredMountPoints: defIgnoredMountPoints,
+		IgnoredFSTypes:     defIgnoredFSTypes,
+		MountTimeout:       5 * time.Second,
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid filesystem options: %w", err)
+	}
+
+	logger.Debugf("filesystem ignored-mount-points: %s", opts.IgnoredMountPoints)
+	mountPointPattern, err := regexp.Compile(opts.IgnoredMountPoints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filesystem ignored-mount-points: %w", err)
+	}
+	logger.Debugf("filesystem ignored-fs-types: %s", opts.IgnoredFSTypes)
+	filesystemsTypesPattern, err := regexp.Compile(opts.IgnoredFSTypes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filesystem ignored-fs-types: %w", err)
+	}
 
 	sizeDesc := prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, "size_bytes"),
@@ -100,6 +123,7 @@ func NewFilesystemCollector(logger log.Logger) (gateway.Collector, error) {
 	return &filesystemCollector{
 		ignoredMountPointsPattern: mountPointPattern,
 		ignoredFSTypesPattern:     filesystemsTypesPattern,
+		mountTimeout:              opts.MountTimeout,
 		sizeDesc:                  sizeDesc,
 		freeDesc:                  freeDesc,
 		availDesc:                 availDesc,
@@ -8,7 +8,6 @@ import (
 	"fildr-cli/internal/log"
 	"fmt"
 	"golang.org/x/sys/unix"
-	"gopkg.in/alecthomas/kingpin.v2"
 	"io"
 	"os"
 	"strings"
@@ -21,9 +20,6 @@ const (
 	defIgnoredFSTypes     = "^(autofs|binfmt_misc|bpf|cgroup2?|configfs|debugfs|devpts|devtmpfs|fusectl|hugetlbfs|iso9660|mqueue|nsfs|overlay|proc|procfs|pstore|rpc_pipefs|securityfs|selinuxfs|squashfs|sysfs|tracefs)$"
 )
 
-var mountTimeout = kingpin.Flag("collector.filesystem.mount-timeout",
-	"how long to wait for a mount to respond before marking it as stale").
-	Hidden().Default("5s").Duration()
 var stuckMounts = make(map[string]struct{})
 var stuckMountsMtx = &sync.Mutex{}
 
@@ -58,7 +54,7 @@ func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
 		// The success channel is used do tell the "watcher" that the stat
 		// finished successfully. The channel is closed on success.
 		success := make(chan struct{})
-		go stuckMountWatcher(labels.mountPoint, success, c.logger)
+		go stuckMountWatcher(labels.mountPoint, c.mountTimeout, success, c.logger)
 
 		buf := new(unix.Statfs_t)
 		err = unix.Statfs(rootfsFilePath(labels.mountPoint), buf)
@@ -105,11 +101,11 @@ func (c *filesystemCollector) GetStats() ([]filesystemStats, error) {
 // stuckMountWatcher listens on the given success channel and if the channel closes
 // then the watcher does nothing. If instead the timeout is reached, the
 // mount point that is being watched is marked as stuck.
-func stuckMountWatcher(mountPoint string, success chan struct{}, logger log.Logger) {
+func stuckMountWatcher(mountPoint string, timeout time.Duration, success chan struct{}, logger log.Logger) {
 	select {
 	case <-success:
 		// Success
-	case <-time.After(*mountTimeout):
+	case <-time.After(timeout):
 		// Timed out, mark mount as stuck
 		stuckMountsMtx.Lock()
 		select {
@@ -5,6 +5,7 @@ package node
 import (
 	"bytes"
 	"encoding/csv"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -31,10 +32,10 @@ var (
 )
 
 func init() {
-	registerCollector("gpu", NewNvidiaCollector)
+	registerCollector("gpu", defaultEnabled, NewNvidiaCollector)
 }
 
-func NewNvidiaCollector(logger log.Logger) (gateway.Collector, error) {
+func NewNvidiaCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	namespace := "nvidia"
 	nc := &nvidiaCollector{
 		numDevices: prometheus.NewDesc(
@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,7 +30,7 @@ var (
 )
 
 func init() {
-	registerCollector("hwmon", NewHwMonCollector)
+	registerCollector("hwmon", defaultEnabled, NewHwMonCollector)
 }
 
 type hwMonCollector struct {
@@ -38,7 +39,7 @@ type hwMonCollector struct {
 
 // NewHwMonCollector returns a new Collector exposing /sys/class/hwmon stats
 // (similar to lm-sensors).
-func NewHwMonCollector(logger log.Logger) (gateway.Collector, error) {
+func NewHwMonCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &hwMonCollector{logger}, nil
 }
 
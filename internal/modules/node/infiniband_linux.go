@@ -5,6 +5,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -22,11 +23,11 @@ type infinibandCollector struct {
 }
 
 func init() {
-	registerCollector("infiniband", NewInfiniBandCollector)
+	registerCollector("infiniband", defaultEnabled, NewInfiniBandCollector)
 }
 
 // NewInfiniBandCollector returns a new Collector exposing InfiniBand stats.
-func NewInfiniBandCollector(logger log.Logger) (gateway.Collector, error) {
+func NewInfiniBandCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	var i infinibandCollector
 	var err error
 
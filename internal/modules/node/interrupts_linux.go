@@ -6,6 +6,7 @@ package node
 import (
 	"bufio"
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -26,11 +27,11 @@ type interruptsCollector struct {
 }
 
 func init() {
-	//registerCollector("interrupts", NewInterruptsCollector)
+	registerCollector("interrupts", defaultDisabled, NewInterruptsCollector)
 }
 
 // NewInterruptsCollector returns a new Collector exposing interrupts stats.
-func NewInterruptsCollector(logger log.Logger) (gateway.Collector, error) {
+func NewInterruptsCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &interruptsCollector{
 		desc: gateway.TypedDesc{prometheus.NewDesc(
 			namespace+"_interrupts_total",
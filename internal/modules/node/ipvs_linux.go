@@ -4,12 +4,12 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
-	"gopkg.in/alecthomas/kingpin.v2"
 	"os"
 	"sort"
 	"strconv"
@@ -25,6 +25,11 @@ type ipvsCollector struct {
 	logger                                                                      log.Logger
 }
 
+type ipvsOptions struct {
+	// Labels for IPVS backend stats, either a list or a comma separated string.
+	BackendLabels []string `mapstructure:"backend-labels"`
+}
+
 type ipvsBackendStatus struct {
 	ActiveConn uint64
 	InactConn  uint64
@@ -49,27 +54,32 @@ var (
 		ipvsLabelProto,
 		ipvsLabelLocalMark,
 	}
-	ipvsLabels = kingpin.Flag("collector.ipvs.backend-labels", "Comma separated list for IPVS backend stats labels.").Default(strings.Join(fullIpvsBackendLabels, ",")).String()
 )
 
 func init() {
-	registerCollector("ipvs", NewIPVSCollector)
+	registerCollector("ipvs", defaultEnabled, NewIPVSCollector)
 }
 
 // NewIPVSCollector sets up a new collector for IPVS metrics. It accepts the
 // "procfs" config parameter to override the default proc location (/proc).
-func NewIPVSCollector(logger log.Logger) (gateway.Collector, error) {
-	return newIPVSCollector(logger)
+func NewIPVSCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
+	opts := ipvsOptions{
+		BackendLabels: fullIpvsBackendLabels,
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid ipvs options: %w", err)
+	}
+	return newIPVSCollector(logger, opts)
 }
 
-func newIPVSCollector(logger log.Logger) (*ipvsCollector, error) {
+func newIPVSCollector(logger log.Logger, opts ipvsOptions) (*ipvsCollector, error) {
 	var (
 		c         ipvsCollector
 		err       error
 		subsystem = "ipvs"
 	)
 
-	if c.backendLabels, err = c.parseIpvsLabels(*ipvsLabels); err != nil {
+	if c.backendLabels, err = c.parseIpvsLabels(strings.Join(opts.BackendLabels, ",")); err != nil {
 		return nil, err
 	}
 
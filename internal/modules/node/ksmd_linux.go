@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -21,7 +22,7 @@ type ksmdCollector struct {
 }
 
 func init() {
-	//registerCollector("ksmd", NewKsmdCollector)
+	registerCollector("ksmd", defaultDisabled, NewKsmdCollector)
 }
 
 func getCanonicalMetricName(filename string) string {
@@ -36,7 +37,7 @@ func getCanonicalMetricName(filename string) string {
 }
 
 // NewKsmdCollector returns a new Collector exposing kernel/system statistics.
-func NewKsmdCollector(logger log.Logger) (gateway.Collector, error) {
+func NewKsmdCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	subsystem := "ksmd"
 	descs := make(map[string]*prometheus.Desc)
 
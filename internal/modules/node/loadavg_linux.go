@@ -4,6 +4,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -19,11 +20,11 @@ type loadavgCollector struct {
 }
 
 func init() {
-	registerCollector("loadavg", NewLoadavgCollector)
+	registerCollector("loadavg", defaultEnabled, NewLoadavgCollector)
 }
 
 // NewLoadavgCollector returns a new Collector exposing load average stats.
-func NewLoadavgCollector(logger log.Logger) (gateway.Collector, error) {
+func NewLoadavgCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &loadavgCollector{
 		metric: []gateway.TypedDesc{
 			{prometheus.NewDesc(namespace+"_load1", "1m load average.", nil, nil), prometheus.GaugeValue},
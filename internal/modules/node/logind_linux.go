@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -68,11 +69,11 @@ type logindSeatEntry struct {
 }
 
 func init() {
-	//registerCollector("logind", NewLogindCollector)
+	registerCollector("logind", defaultDisabled, NewLogindCollector)
 }
 
 // NewLogindCollector returns a new Collector exposing logind statistics.
-func NewLogindCollector(logger log.Logger) (gateway.Collector, error) {
+func NewLogindCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &logindCollector{logger}, nil
 }
 
@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -17,11 +18,11 @@ type mdadmCollector struct {
 }
 
 func init() {
-	registerCollector("mdadm", NewMdadmCollector)
+	registerCollector("mdadm", defaultEnabled, NewMdadmCollector)
 }
 
 // NewMdadmCollector returns a new Collector exposing raid statistics.
-func NewMdadmCollector(logger log.Logger) (gateway.Collector, error) {
+func NewMdadmCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &mdadmCollector{logger}, nil
 }
 
@@ -5,6 +5,7 @@ package node
 
 import (
 	"bufio"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -29,11 +30,11 @@ type meminfoCollector struct {
 }
 
 func init() {
-	registerCollector("meminfo", NewMeminfoCollector)
+	registerCollector("meminfo", defaultEnabled, NewMeminfoCollector)
 }
 
 // NewMeminfoCollector returns a new Collector exposing memory stats.
-func NewMeminfoCollector(logger log.Logger) (gateway.Collector, error) {
+func NewMeminfoCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &meminfoCollector{logger}, nil
 }
 
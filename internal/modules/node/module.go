@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fildr-cli/internal/module"
@@ -9,13 +10,20 @@ import (
 
 var _ module.Module = (*NodeCollectorModule)(nil)
 
+const (
+	defaultEnabled  = true
+	defaultDisabled = false
+)
+
 var (
-	namespace = "node"
-	factories = make(map[string]func(logger log.Logger) (gateway.Collector, error))
+	namespace      = "node"
+	factories      = make(map[string]func(logger log.Logger, cfg config.Collector) (gateway.Collector, error))
+	collectorState = make(map[string]bool)
 )
 
-func registerCollector(collector string, factory func(logger log.Logger) (gateway.Collector, error)) {
+func registerCollector(collector string, isDefaultEnabled bool, factory func(logger log.Logger, cfg config.Collector) (gateway.Collector, error)) {
 	factories[collector] = factory
+	collectorState[collector] = isDefaultEnabled
 }
 
 type NodeCollectorModule struct {
@@ -33,10 +41,17 @@ func (mod *NodeCollectorModule) Name() string {
 
 func (mod *NodeCollectorModule) Start() error {
 	mod.logger.Infof("node collector starting ...")
+	cfg := config.Get()
 	for k, c := range factories {
-		collector, err := c(mod.logger)
+		collectorCfg := cfg.Collectors[k]
+		if !collectorCfg.Enabled(collectorState[k]) {
+			mod.logger.Debugf("collector %s is disabled", k)
+			continue
+		}
+		collector, err := c(mod.logger, collectorCfg)
 		if err != nil {
 			mod.logger.Warnf("collector %s is err: %v", k, err)
+			gateway.RegistryFailed("node", k)
 			continue
 		}
 		gateway.Registry("node", k, collector)
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -92,11 +93,11 @@ type nfsDeviceIdentifier struct {
 }
 
 func init() {
-	//registerCollector("mountstats", NewMountStatsCollector)
+	registerCollector("mountstats", defaultDisabled, NewMountStatsCollector)
 }
 
 // NewMountStatsCollector returns a new Collector exposing NFS statistics.
-func NewMountStatsCollector(logger log.Logger) (gateway.Collector, error) {
+func NewMountStatsCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
@@ -4,6 +4,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -12,9 +13,10 @@ import (
 	"regexp"
 )
 
-var (
-	netclassIgnoredDevices = "^$"
-)
+type netClassOptions struct {
+	// Regexp of net devices to ignore for netclass collector.
+	IgnoredDevices string `mapstructure:"ignored-devices"`
+}
 
 type netClassCollector struct {
 	fs                    sysfs.FS
@@ -25,16 +27,25 @@ type netClassCollector struct {
 }
 
 func init() {
-	registerCollector("netclass", NewNetClassCollector)
+	registerCollector("netclass", defaultEnabled, NewNetClassCollector)
 }
 
 // NewNetClassCollector returns a new Collector exposing network class stats.
-func NewNetClassCollector(logger log.Logger) (gateway.Collector, error) {
+func NewNetClassCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := sysfs.NewFS(sysPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sysfs: %w", err)
 	}
-	pattern := regexp.MustCompile(netclassIgnoredDevices)
+	opts := netClassOptions{
+		IgnoredDevices: "^$",
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid netclass options: %w", err)
+	}
+	pattern, err := regexp.Compile(opts.IgnoredDevices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid netclass ignored-devices: %w", err)
+	}
 	return &netClassCollector{
 		fs:                    fs,
 		subsystem:             "network",
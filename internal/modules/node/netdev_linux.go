@@ -6,6 +6,7 @@ package node
 import (
 	"bufio"
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -17,18 +18,16 @@ import (
 	"strings"
 )
 
-var (
-	netdevDeviceInclude    = ""
-	oldNetdevDeviceInclude = ""
-	netdevDeviceExclude    = ""
-	oldNetdevDeviceExclude = ""
-)
-
 var (
 	procNetDevInterfaceRE = regexp.MustCompile(`^(.+): *(.+)$`)
 	procNetDevFieldSep    = regexp.MustCompile(` +`)
 )
 
+type netDevOptions struct {
+	DeviceInclude string `mapstructure:"device-include"`
+	DeviceExclude string `mapstructure:"device-exclude"`
+}
+
 type netDevCollector struct {
 	subsystem            string
 	deviceExcludePattern *regexp.Regexp
@@ -38,43 +37,38 @@ type netDevCollector struct {
 }
 
 func init() {
-	registerCollector("netdev", NewNetDevCollector)
+	registerCollector("netdev", defaultEnabled, NewNetDevCollector)
 }
 
 // NewNetDevCollector returns a new Collector exposing network device stats.
-func NewNetDevCollector(logger log.Logger) (gateway.Collector, error) {
-	if oldNetdevDeviceInclude != "" {
-		if netdevDeviceInclude == "" {
-			logger.Warnf("msg", "--collector.netdev.device-whitelist is DEPRECATED and will be removed in 2.0.0, use --collector.netdev.device-include")
-			netdevDeviceInclude = oldNetdevDeviceInclude
-		} else {
-			return nil, errors.New("--collector.netdev.device-whitelist and --collector.netdev.device-include are mutually exclusive")
-		}
+func NewNetDevCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
+	opts := netDevOptions{}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid netdev options: %w", err)
 	}
 
-	if oldNetdevDeviceExclude != "" {
-		if netdevDeviceExclude == "" {
-			logger.Warnf("msg", "--collector.netdev.device-blacklist is DEPRECATED and will be removed in 2.0.0, use --collector.netdev.device-exclude")
-			netdevDeviceExclude = oldNetdevDeviceExclude
-		} else {
-			return nil, errors.New("--collector.netdev.device-blacklist and --collector.netdev.device-exclude are mutually exclusive")
-		}
-	}
-
-	if netdevDeviceExclude != "" && netdevDeviceInclude != "" {
+	if opts.DeviceExclude != "" && opts.DeviceInclude != "" {
 		return nil, errors.New("device-exclude & device-include are mutually exclusive")
 	}
 
 	var excludePattern *regexp.Regexp
-	if netdevDeviceExclude != "" {
-		logger.Infof("msg", "Parsed flag --collector.netdev.device-exclude", "flag", netdevDeviceExclude)
-		excludePattern = regexp.MustCompile(netdevDeviceExclude)
+	if opts.DeviceExclude != "" {
+		logger.Debugf("netdev device-exclude: %s", opts.DeviceExclude)
+		pattern, err := regexp.Compile(opts.DeviceExclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid netdev device-exclude: %w", err)
+		}
+		excludePattern = pattern
 	}
 
 	var includePattern *regexp.Regexp
-	if netdevDeviceInclude != "" {
-		logger.Infof("msg", "Parsed Flag --collector.netdev.device-include", "flag", netdevDeviceInclude)
-		includePattern = regexp.MustCompile(netdevDeviceInclude)
+	if opts.DeviceInclude != "" {
+		logger.Debugf("netdev device-include: %s", opts.DeviceInclude)
+		pattern, err := regexp.Compile(opts.DeviceInclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid netdev device-include: %w", err)
+		}
+		includePattern = pattern
 	}
 
 	return &netDevCollector{
@@ -5,6 +5,7 @@ package node
 import (
 	"bufio"
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -30,12 +31,12 @@ type netStatCollector struct {
 }
 
 func init() {
-	registerCollector("netstat", NewNetStatCollector)
+	registerCollector("netstat", defaultEnabled, NewNetStatCollector)
 }
 
 // NewNetStatCollector takes and returns
 // a new Collector exposing network stats.
-func NewNetStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewNetStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	pattern := regexp.MustCompile(netStatFields)
 	return &netStatCollector{
 		fieldPattern: pattern,
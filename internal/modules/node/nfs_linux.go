@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -29,11 +30,11 @@ type nfsCollector struct {
 }
 
 func init() {
-	registerCollector("nfs", NewNfsCollector)
+	registerCollector("nfs", defaultEnabled, NewNfsCollector)
 }
 
 // NewNfsCollector returns a new Collector exposing NFS statistics.
-func NewNfsCollector(logger log.Logger) (gateway.Collector, error) {
+func NewNfsCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := nfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
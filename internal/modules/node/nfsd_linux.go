@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -21,7 +22,7 @@ type nfsdCollector struct {
 }
 
 func init() {
-	registerCollector("nfsd", NewNFSdCollector)
+	registerCollector("nfsd", defaultEnabled, NewNFSdCollector)
 }
 
 const (
@@ -29,7 +30,7 @@ const (
 )
 
 // NewNFSdCollector returns a new Collector exposing /proc/net/rpc/nfsd statistics.
-func NewNFSdCollector(logger log.Logger) (gateway.Collector, error) {
+func NewNFSdCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := nfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -19,43 +20,58 @@ const (
 )
 
 var (
-	ntpServer          = "127.0.0.1"
-	ntpProtocolVersion = 4
-	ntpServerIsLocal   = false
-	ntpIPTTL           = 1
-	// 3.46608s ~ 1.5s + PHI * (1 << maxPoll), where 1.5s is MAXDIST from ntp.org, it is 1.0 in RFC5905
-	// max-distance option is used as-is without phi*(1<<poll)
-	ntpMaxDistance, _     = time.ParseDuration("3.46608s")
-	ntpOffsetTolerance, _ = time.ParseDuration("1ms")
-
 	leapMidnight      time.Time
 	leapMidnightMutex = &sync.Mutex{}
 )
 
+type ntpOptions struct {
+	Server          string `mapstructure:"server"`
+	ProtocolVersion int    `mapstructure:"protocol-version"`
+	// Treat a non-loopback server address as the local NTP server.
+	ServerIsLocal bool `mapstructure:"server-is-local"`
+	IPTTL         int  `mapstructure:"ip-ttl"`
+	// 3.46608s ~ 1.5s + PHI * (1 << maxPoll), where 1.5s is MAXDIST from ntp.org, it is 1.0 in RFC5905
+	// max-distance option is used as-is without phi*(1<<poll)
+	MaxDistance     time.Duration `mapstructure:"max-distance"`
+	OffsetTolerance time.Duration `mapstructure:"local-offset-tolerance"`
+}
+
 type ntpCollector struct {
 	stratum, leap, rtt, offset, reftime, rootDelay, rootDispersion, sanity gateway.TypedDesc
+	opts                                                                   ntpOptions
 	logger                                                                 log.Logger
 }
 
 func init() {
-	//registerCollector("ntp", NewNtpCollector)
+	registerCollector("ntp", defaultDisabled, NewNtpCollector)
 }
 
 // NewNtpCollector returns a new Collector exposing sanity of local NTP server.
 // Default definition of "local" is:
-// - collector.ntp.server address is a loopback address (or collector.ntp.server-is-mine flag is turned on)
+// - the server option is a loopback address (or the server-is-local option is turned on)
 // - the server is reachable with outgoin IP_TTL = 1
-func NewNtpCollector(logger log.Logger) (gateway.Collector, error) {
-	ipaddr := net.ParseIP(ntpServer)
-	if !ntpServerIsLocal && (ipaddr == nil || !ipaddr.IsLoopback()) {
-		return nil, fmt.Errorf("only IP address of local NTP server is valid for --collector.ntp.server")
+func NewNtpCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
+	opts := ntpOptions{
+		Server:          "127.0.0.1",
+		ProtocolVersion: 4,
+		IPTTL:           1,
+		MaxDistance:     3466080 * time.Microsecond,
+		OffsetTolerance: time.Millisecond,
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid ntp options: %w", err)
+	}
+
+	ipaddr := net.ParseIP(opts.Server)
+	if !opts.ServerIsLocal && (ipaddr == nil || !ipaddr.IsLoopback()) {
+		return nil, fmt.Errorf("only IP address of local NTP server is valid for the ntp server option")
 	}
 
-	if ntpProtocolVersion < 2 || ntpProtocolVersion > 4 {
-		return nil, fmt.Errorf("invalid NTP protocol version %d; must be 2, 3, or 4", ntpProtocolVersion)
+	if opts.ProtocolVersion < 2 || opts.ProtocolVersion > 4 {
+		return nil, fmt.Errorf("invalid NTP protocol version %d; must be 2, 3, or 4", opts.ProtocolVersion)
 	}
 
-	if ntpOffsetTolerance < 0 {
+	if opts.OffsetTolerance < 0 {
 		return nil, fmt.Errorf("offset tolerance must be non-negative")
 	}
 
@@ -100,14 +116,15 @@ func NewNtpCollector(logger log.Logger) (gateway.Collector, error) {
 			"NTPD sanity according to RFC5905 heuristics and configured limits.",
 			nil, nil,
 		), prometheus.GaugeValue},
+		opts:   opts,
 		logger: logger,
 	}, nil
 }
 
 func (c *ntpCollector) Update(ch chan<- prometheus.Metric) error {
-	resp, err := ntp.QueryWithOptions(ntpServer, ntp.QueryOptions{
-		Version: ntpProtocolVersion,
-		TTL:     ntpIPTTL,
+	resp, err := ntp.QueryWithOptions(c.opts.Server, ntp.QueryOptions{
+		Version: c.opts.ProtocolVersion,
+		TTL:     c.opts.IPTTL,
 		Timeout: time.Second, // default `ntpdate` timeout
 	})
 	if err != nil {
@@ -133,7 +150,7 @@ func (c *ntpCollector) Update(ch chan<- prometheus.Metric) error {
 	// Here is SNTP packet sanity check that is exposed to move burden of
 	// configuration from node_exporter user to the developer.
 
-	maxerr := ntpOffsetTolerance
+	maxerr := c.opts.OffsetTolerance
 	leapMidnightMutex.Lock()
 	if resp.Leap == ntp.LeapAddSecond || resp.Leap == ntp.LeapDelSecond {
 		// state of leapMidnight is cached as leap flag is dropped right after midnight
@@ -145,7 +162,7 @@ func (c *ntpCollector) Update(ch chan<- prometheus.Metric) error {
 	}
 	leapMidnightMutex.Unlock()
 
-	if resp.Validate() == nil && resp.RootDistance <= ntpMaxDistance && resp.MinError <= maxerr {
+	if resp.Validate() == nil && resp.RootDistance <= c.opts.MaxDistance && resp.MinError <= maxerr {
 		ch <- c.sanity.MustNewConstMetric(1)
 	} else {
 		ch <- c.sanity.MustNewConstMetric(0)
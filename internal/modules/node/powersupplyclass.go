@@ -5,19 +5,20 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs/sysfs"
-	"gopkg.in/alecthomas/kingpin.v2"
 	"os"
 	"regexp"
 )
 
-var (
-	powerSupplyClassIgnoredPowerSupplies = kingpin.Flag("collector.powersupply.ignored-supplies", "Regexp of power supplies to ignore for powersupplyclass collector.").Default("^$").String()
-)
+type powerSupplyClassOptions struct {
+	// Regexp of power supplies to ignore for powersupplyclass collector.
+	IgnoredSupplies string `mapstructure:"ignored-supplies"`
+}
 
 type powerSupplyClassCollector struct {
 	subsystem      string
@@ -27,11 +28,20 @@ type powerSupplyClassCollector struct {
 }
 
 func init() {
-	registerCollector("powersupplyclass", NewPowerSupplyClassCollector)
+	registerCollector("powersupplyclass", defaultEnabled, NewPowerSupplyClassCollector)
 }
 
-func NewPowerSupplyClassCollector(logger log.Logger) (gateway.Collector, error) {
-	pattern := regexp.MustCompile(*powerSupplyClassIgnoredPowerSupplies)
+func NewPowerSupplyClassCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
+	opts := powerSupplyClassOptions{
+		IgnoredSupplies: "^$",
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid powersupplyclass options: %w", err)
+	}
+	pattern, err := regexp.Compile(opts.IgnoredSupplies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid powersupplyclass ignored-supplies: %w", err)
+	}
 	return &powerSupplyClassCollector{
 		subsystem:      "power_supply",
 		ignoredPattern: pattern,
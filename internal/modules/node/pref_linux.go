@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -18,13 +19,15 @@ const (
 	perfSubsystem = "perf"
 )
 
-var (
-	perfCPUsFlag       = ""
-	perfTracepointFlag = []string{}
-)
+type perfOptions struct {
+	// List of CPUs from which perf metrics should be collected, e.g. "1-3,5".
+	CPUs string `mapstructure:"cpus"`
+	// perf tracepoints that should be collected, e.g. "sched:sched_kthread_stop".
+	Tracepoints []string `mapstructure:"tracepoints"`
+}
 
 func init() {
-	//registerCollector(perfSubsystem, NewPerfCollector)
+	registerCollector(perfSubsystem, defaultDisabled, NewPerfCollector)
 }
 
 // perfTracepointFlagToTracepoints returns the set of configured tracepoints.
@@ -229,7 +232,7 @@ func newPerfTracepointCollector(
 
 // NewPerfCollector returns a new perf based collector, it creates a profiler
 // per CPU.
-func NewPerfCollector(logger log.Logger) (gateway.Collector, error) {
+func NewPerfCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	collector := &perfCollector{
 		perfHwProfilers:     map[int]*perf.HardwareProfiler{},
 		perfSwProfilers:     map[int]*perf.SoftwareProfiler{},
@@ -240,12 +243,17 @@ func NewPerfCollector(logger log.Logger) (gateway.Collector, error) {
 		logger:              logger,
 	}
 
+	opts := perfOptions{}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid perf options: %w", err)
+	}
+
 	var (
 		cpus []int
 		err  error
 	)
-	if perfCPUsFlag != "" {
-		cpus, err = perfCPUFlagToCPUs(perfCPUsFlag)
+	if opts.CPUs != "" {
+		cpus, err = perfCPUFlagToCPUs(opts.CPUs)
 		if err != nil {
 			return nil, err
 		}
@@ -257,8 +265,8 @@ func NewPerfCollector(logger log.Logger) (gateway.Collector, error) {
 	}
 
 	// First configure any tracepoints.
-	if perfTracepointFlag != nil && len(perfTracepointFlag) > 0 {
-		tracepointCollector, err := newPerfTracepointCollector(logger, perfTracepointFlag, cpus)
+	if len(opts.Tracepoints) > 0 {
+		tracepointCollector, err := newPerfTracepointCollector(logger, opts.Tracepoints, cpus)
 		if err != nil {
 			return nil, err
 		}
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -27,11 +28,11 @@ type pressureStatsCollector struct {
 }
 
 func init() {
-	registerCollector("pressure", NewPressureStatsCollector)
+	registerCollector("pressure", defaultEnabled, NewPressureStatsCollector)
 }
 
 // NewPressureStatsCollector returns a Collector exposing pressure stall information
-func NewPressureStatsCollector(logger log.Logger) (gateway.Collector, error) {
+func NewPressureStatsCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
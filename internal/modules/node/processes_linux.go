@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -23,11 +24,11 @@ type processCollector struct {
 }
 
 func init() {
-	//registerCollector("processes", NewProcessStatCollector)
+	registerCollector("processes", defaultDisabled, NewProcessStatCollector)
 }
 
 // NewProcessStatCollector returns a new Collector exposing process data read from the proc filesystem.
-func NewProcessStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewProcessStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
@@ -4,6 +4,7 @@ package node
 
 import (
 	"encoding/json"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/ema/qdisc"
@@ -28,11 +29,11 @@ var (
 )
 
 func init() {
-	//registerCollector("qdisc", NewQdiscStatCollector)
+	registerCollector("qdisc", defaultDisabled, NewQdiscStatCollector)
 }
 
 // NewQdiscStatCollector returns a new Collector exposing queuing discipline statistics.
-func NewQdiscStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewQdiscStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &qdiscStatCollector{
 		bytes: gateway.TypedDesc{prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "qdisc", "bytes_total"),
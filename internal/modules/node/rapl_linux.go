@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,11 +16,11 @@ type raplCollector struct {
 }
 
 func init() {
-	registerCollector("rapl", NewRaplCollector)
+	registerCollector("rapl", defaultEnabled, NewRaplCollector)
 }
 
 // NewRaplCollector returns a new Collector exposing RAPL metrics.
-func NewRaplCollector(logger log.Logger) (gateway.Collector, error) {
+func NewRaplCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := sysfs.NewFS(sysPath)
 
 	if err != nil {
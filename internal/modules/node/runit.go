@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,11 +21,11 @@ type runitCollector struct {
 }
 
 func init() {
-	//registerCollector("runit", NewRunitCollector)
+	registerCollector("runit", defaultDisabled, NewRunitCollector)
 }
 
 // NewRunitCollector returns a new Collector exposing runit statistics.
-func NewRunitCollector(logger log.Logger) (gateway.Collector, error) {
+func NewRunitCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	var (
 		subsystem   = "service"
 		constLabels = prometheus.Labels{"supervisor": "runit"}
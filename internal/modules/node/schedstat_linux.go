@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -38,7 +39,7 @@ var (
 )
 
 // NewSchedstatCollector returns a new Collector exposing task scheduler statistics
-func NewSchedstatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewSchedstatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
@@ -53,7 +54,7 @@ type schedstatCollector struct {
 }
 
 func init() {
-	registerCollector("schedstat", NewSchedstatCollector)
+	registerCollector("schedstat", defaultEnabled, NewSchedstatCollector)
 }
 
 func (c *schedstatCollector) Update(ch chan<- prometheus.Metric) error {
@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -24,11 +25,11 @@ type sockStatCollector struct {
 }
 
 func init() {
-	registerCollector(sockStatSubsystem, NewSockStatCollector)
+	registerCollector(sockStatSubsystem, defaultEnabled, NewSockStatCollector)
 }
 
 // NewSockStatCollector returns a new Collector exposing socket stats.
-func NewSockStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewSockStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &sockStatCollector{logger}, nil
 }
 
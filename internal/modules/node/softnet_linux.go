@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -24,11 +25,11 @@ const (
 )
 
 func init() {
-	registerCollector("softnet", NewSoftnetCollector)
+	registerCollector("softnet", defaultEnabled, NewSoftnetCollector)
 }
 
 // NewSoftnetCollector returns a new Collector exposing softnet metrics.
-func NewSoftnetCollector(logger log.Logger) (gateway.Collector, error) {
+func NewSoftnetCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
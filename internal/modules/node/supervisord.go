@@ -4,6 +4,7 @@ package node
 
 import (
 	"context"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -16,10 +17,14 @@ import (
 )
 
 var (
-	supervisordURL = "http://localhost:9001/RPC2"
-	xrpc           *xmlrpc.Client
+	xrpc *xmlrpc.Client
 )
 
+type supervisordOptions struct {
+	// XML RPC endpoint, unix:// URLs are dialed as unix sockets.
+	URL string `mapstructure:"url"`
+}
+
 type supervisordCollector struct {
 	upDesc         *prometheus.Desc
 	stateDesc      *prometheus.Desc
@@ -29,17 +34,24 @@ type supervisordCollector struct {
 }
 
 func init() {
-	//registerCollector("supervisord", NewSupervisordCollector)
+	registerCollector("supervisord", defaultDisabled, NewSupervisordCollector)
 }
 
 // NewSupervisordCollector returns a new Collector exposing supervisord statistics.
-func NewSupervisordCollector(logger log.Logger) (gateway.Collector, error) {
+func NewSupervisordCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	var (
 		subsystem  = "supervisord"
 		labelNames = []string{"name", "group"}
 	)
 
-	if u, err := url.Parse(supervisordURL); err == nil && u.Scheme == "unix" {
+	opts := supervisordOptions{
+		URL: "http://localhost:9001/RPC2",
+	}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid supervisord options: %w", err)
+	}
+
+	if u, err := url.Parse(opts.URL); err == nil && u.Scheme == "unix" {
 		// Fake the URI scheme as http, since net/http.*Transport.roundTrip will complain
 		// about a non-http(s) transport.
 		xrpc = xmlrpc.NewClient("http://unix/RPC2")
@@ -50,7 +62,7 @@ func NewSupervisordCollector(logger log.Logger) (gateway.Collector, error) {
 			},
 		}
 	} else {
-		xrpc = xmlrpc.NewClient(supervisordURL)
+		xrpc = xmlrpc.NewClient(opts.URL)
 	}
 
 	return &supervisordCollector{
@@ -3,7 +3,7 @@
 package node
 
 import (
-	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -24,16 +24,15 @@ const (
 	minSystemdVersionSystemState = 212
 )
 
-var (
-	unitInclude            = ".+"
-	oldUnitInclude         = ""
-	unitExclude            = ".+\\.(automount|device|mount|scope|slice)"
-	oldUnitExclude         = ""
-	systemdPrivate         = false
-	enableTaskMetrics      = false
-	enableRestartsMetrics  = false
-	enableStartTimeMetrics = false
-)
+type systemdOptions struct {
+	UnitInclude string `mapstructure:"unit-include"`
+	UnitExclude string `mapstructure:"unit-exclude"`
+	// Connect directly to the systemd private socket instead of dbus.
+	Private                bool `mapstructure:"private"`
+	EnableTaskMetrics      bool `mapstructure:"enable-task-metrics"`
+	EnableRestartsMetrics  bool `mapstructure:"enable-restarts-metrics"`
+	EnableStartTimeMetrics bool `mapstructure:"enable-start-time-metrics"`
+}
 
 type systemdCollector struct {
 	unitDesc                      *prometheus.Desc
@@ -51,17 +50,18 @@ type systemdCollector struct {
 	systemdVersion                int
 	unitIncludePattern            *regexp.Regexp
 	unitExcludePattern            *regexp.Regexp
+	opts                          systemdOptions
 	logger                        log.Logger
 }
 
 var unitStatesName = []string{"active", "activating", "deactivating", "inactive", "failed"}
 
 func init() {
-	//registerCollector("systemd", NewSystemdCollector)
+	registerCollector("systemd", defaultDisabled, NewSystemdCollector)
 }
 
 // NewSystemdCollector returns a new Collector exposing systemd statistics.
-func NewSystemdCollector(logger log.Logger) (gateway.Collector, error) {
+func NewSystemdCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	const subsystem = "systemd"
 
 	unitDesc := prometheus.NewDesc(
@@ -107,28 +107,26 @@ func NewSystemdCollector(logger log.Logger) (gateway.Collector, error) {
 		prometheus.BuildFQName(namespace, subsystem, "version"),
 		"Detected systemd version", []string{}, nil)
 
-	if oldUnitExclude != "" {
-		if unitExclude == "" {
-			logger.Warnf("msg", "--collector.systemd.unit-blacklist is DEPRECATED and will be removed in 2.0.0, use --collector.systemd.unit-exclude")
-			unitExclude = oldUnitExclude
-		} else {
-			return nil, errors.New("--collector.systemd.unit-blacklist and --collector.systemd.unit-exclude are mutually exclusive")
-		}
+	opts := systemdOptions{
+		UnitInclude: ".+",
+		UnitExclude: ".+\\.(automount|device|mount|scope|slice)",
 	}
-	if oldUnitInclude != "" {
-		if unitInclude == "" {
-			logger.Warnf("msg", "--collector.systemd.unit-whitelist is DEPRECATED and will be removed in 2.0.0, use --collector.systemd.unit-include")
-			unitInclude = oldUnitInclude
-		} else {
-			return nil, errors.New("--collector.systemd.unit-whitelist and --collector.systemd.unit-include are mutually exclusive")
-		}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid systemd options: %w", err)
+	}
+
+	logger.Debugf("systemd unit-include: %s", opts.UnitInclude)
+	unitIncludePattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", opts.UnitInclude))
+	if err != nil {
+		return nil, fmt.Errorf("invalid systemd unit-include: %w", err)
+	}
+	logger.Debugf("systemd unit-exclude: %s", opts.UnitExclude)
+	unitExcludePattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", opts.UnitExclude))
+	if err != nil {
+		return nil, fmt.Errorf("invalid systemd unit-exclude: %w", err)
 	}
-	logger.Infof("msg", "Parsed flag --collector.systemd.unit-include", "flag", unitInclude)
-	unitIncludePattern := regexp.MustCompile(fmt.Sprintf("^(?:%s)$", unitInclude))
-	logger.Infof("msg", "Parsed flag --collector.systemd.unit-exclude", "flag", unitExclude)
-	unitExcludePattern := regexp.MustCompile(fmt.Sprintf("^(?:%s)$", unitExclude))
 
-	systemdVersion := getSystemdVersion(logger)
+	systemdVersion := getSystemdVersion(opts.Private, logger)
 	if systemdVersion < minSystemdVersionSystemState {
 		logger.Warnf("msg", "Detected systemd version is lower than minimum", "current", systemdVersion, "minimum", minSystemdVersionSystemState)
 		logger.Warnf("msg", "Some systemd state and timer metrics will not be available")
@@ -150,6 +148,7 @@ func NewSystemdCollector(logger log.Logger) (gateway.Collector, error) {
 		systemdVersion:                systemdVersion,
 		unitIncludePattern:            unitIncludePattern,
 		unitExcludePattern:            unitExcludePattern,
+		opts:                          opts,
 		logger:                        logger,
 	}, nil
 }
@@ -158,7 +157,7 @@ func NewSystemdCollector(logger log.Logger) (gateway.Collector, error) {
 // to reduce wait time for responses.
 func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 	begin := time.Now()
-	conn, err := newSystemdDbusConn()
+	conn, err := newSystemdDbusConn(c.opts.Private)
 	if err != nil {
 		return fmt.Errorf("couldn't get dbus connection: %w", err)
 	}
@@ -190,7 +189,7 @@ func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 		c.logger.Debugf("msg", "collectUnitStatusMetrics took", "duration_seconds", time.Since(begin).Seconds())
 	}()
 
-	if enableStartTimeMetrics {
+	if c.opts.EnableStartTimeMetrics {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -200,7 +199,7 @@ func (c *systemdCollector) Update(ch chan<- prometheus.Metric) error {
 		}()
 	}
 
-	if enableTaskMetrics {
+	if c.opts.EnableTaskMetrics {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -267,7 +266,7 @@ func (c *systemdCollector) collectUnitStatusMetrics(conn *dbus.Conn, ch chan<- p
 				c.unitDesc, prometheus.GaugeValue, isActive,
 				unit.Name, stateName, serviceType)
 		}
-		if enableRestartsMetrics && strings.HasSuffix(unit.Name, ".service") {
+		if c.opts.EnableRestartsMetrics && strings.HasSuffix(unit.Name, ".service") {
 			// NRestarts wasn't added until systemd 235.
 			restartsCount, err := conn.GetUnitTypeProperty(unit.Name, "Service", "NRestarts")
 			if err != nil {
@@ -408,8 +407,8 @@ func (c *systemdCollector) collectSystemState(conn *dbus.Conn, ch chan<- prometh
 	return nil
 }
 
-func newSystemdDbusConn() (*dbus.Conn, error) {
-	if systemdPrivate {
+func newSystemdDbusConn(private bool) (*dbus.Conn, error) {
+	if private {
 		return dbus.NewSystemdConnection()
 	}
 	return dbus.New()
@@ -464,8 +463,8 @@ func filterUnits(units []unit, includePattern, excludePattern *regexp.Regexp, lo
 	return filtered
 }
 
-func getSystemdVersion(logger log.Logger) int {
-	conn, err := newSystemdDbusConn()
+func getSystemdVersion(private bool, logger log.Logger) int {
+	conn, err := newSystemdDbusConn(private)
 	if err != nil {
 		logger.Warnf("msg", "Unable to get systemd dbus connection, defaulting systemd version to 0", "err", err)
 		return 0
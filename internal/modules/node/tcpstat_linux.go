@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -51,11 +52,11 @@ type tcpStatCollector struct {
 }
 
 func init() {
-	//registerCollector("tcpstat", NewTCPStatCollector)
+	registerCollector("tcpstat", defaultDisabled, NewTCPStatCollector)
 }
 
 // NewTCPStatCollector returns a new Collector exposing network stats.
-func NewTCPStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewTCPStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &tcpStatCollector{
 		desc: gateway.TypedDesc{prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "tcp", "connection_states"),
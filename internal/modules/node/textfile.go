@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -18,8 +19,7 @@ import (
 )
 
 var (
-	textFileDirectory = ""
-	mtimeDesc         = prometheus.NewDesc(
+	mtimeDesc = prometheus.NewDesc(
 		"node_textfile_mtime_seconds",
 		"Unixtime mtime of textfiles successfully read.",
 		[]string{"file"},
@@ -27,6 +27,11 @@ var (
 	)
 )
 
+type textFileOptions struct {
+	// Directory to read text files with metrics from.
+	Directory string `mapstructure:"directory"`
+}
+
 type textFileCollector struct {
 	path string
 	// Only set for testing to get predictable output.
@@ -35,14 +40,19 @@ type textFileCollector struct {
 }
 
 func init() {
-	registerCollector("textfile", NewTextFileCollector)
+	registerCollector("textfile", defaultEnabled, NewTextFileCollector)
 }
 
 // NewTextFileCollector returns a new Collector exposing metrics read from files
 // in the given textfile directory.
-func NewTextFileCollector(logger log.Logger) (gateway.Collector, error) {
+func NewTextFileCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
+	opts := textFileOptions{}
+	if err := cfg.Decode(&opts); err != nil {
+		return nil, fmt.Errorf("invalid textfile options: %w", err)
+	}
+
 	c := &textFileCollector{
-		path:   textFileDirectory,
+		path:   opts.Directory,
 		logger: logger,
 	}
 	return c, nil
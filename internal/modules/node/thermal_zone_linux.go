@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -22,11 +23,11 @@ type thermalZoneCollector struct {
 }
 
 func init() {
-	registerCollector("thermal_zone", NewThermalZoneCollector)
+	registerCollector("thermal_zone", defaultEnabled, NewThermalZoneCollector)
 }
 
 // NewThermalZoneCollector returns a new Collector exposing kernel/system statistics.
-func NewThermalZoneCollector(logger log.Logger) (gateway.Collector, error) {
+func NewThermalZoneCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := sysfs.NewFS(sysPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sysfs: %w", err)
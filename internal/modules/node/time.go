@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -15,12 +16,12 @@ type timeCollector struct {
 }
 
 func init() {
-	registerCollector("time", NewTimeCollector)
+	registerCollector("time", defaultEnabled, NewTimeCollector)
 }
 
 // NewTimeCollector returns a new Collector exposing the current system time in
 // seconds since epoch.
-func NewTimeCollector(logger log.Logger) (gateway.Collector, error) {
+func NewTimeCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &timeCollector{
 		desc: prometheus.NewDesc(
 			namespace+"_time_seconds",
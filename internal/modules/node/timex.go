@@ -4,6 +4,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -46,11 +47,11 @@ type timexCollector struct {
 }
 
 func init() {
-	registerCollector("timex", NewTimexCollector)
+	registerCollector("timex", defaultEnabled, NewTimexCollector)
 }
 
 // NewTimexCollector returns a new Collector exposing adjtime(3) stats.
-func NewTimexCollector(logger log.Logger) (gateway.Collector, error) {
+func NewTimexCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	const subsystem = "timex"
 
 	return &timexCollector{
@@ -4,6 +4,7 @@ package node
 
 import (
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -21,11 +22,11 @@ type (
 )
 
 func init() {
-	registerCollector("udp_queues", NewUDPqueuesCollector)
+	registerCollector("udp_queues", defaultEnabled, NewUDPqueuesCollector)
 }
 
 // NewUDPqueuesCollector returns a new Collector exposing network udp queued bytes.
-func NewUDPqueuesCollector(logger log.Logger) (gateway.Collector, error) {
+func NewUDPqueuesCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := procfs.NewFS(procPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
@@ -5,6 +5,7 @@ package node
 
 import (
 	"bytes"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,11 +39,11 @@ type uname struct {
 }
 
 func init() {
-	registerCollector("uname", newUnameCollector)
+	registerCollector("uname", defaultEnabled, newUnameCollector)
 }
 
 // NewUnameCollector returns new unameCollector.
-func newUnameCollector(logger log.Logger) (gateway.Collector, error) {
+func newUnameCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	return &unameCollector{logger}, nil
 }
 
@@ -4,6 +4,7 @@ package node
 
 import (
 	"bufio"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -28,11 +29,11 @@ type vmStatCollector struct {
 }
 
 func init() {
-	registerCollector("vmstat", NewvmStatCollector)
+	registerCollector("vmstat", defaultEnabled, NewvmStatCollector)
 }
 
 // NewvmStatCollector returns a new Collector exposing vmstat stats.
-func NewvmStatCollector(logger log.Logger) (gateway.Collector, error) {
+func NewvmStatCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	pattern := regexp.MustCompile(vmStatFields)
 	return &vmStatCollector{
 		fieldPattern: pattern,
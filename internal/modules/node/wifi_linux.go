@@ -5,6 +5,7 @@ package node
 import (
 	"encoding/json"
 	"errors"
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -38,7 +39,7 @@ var (
 )
 
 func init() {
-	//registerCollector("wifi", NewWifiCollector)
+	registerCollector("wifi", defaultDisabled, NewWifiCollector)
 }
 
 var _ wifiStater = &wifi.Client{}
@@ -52,7 +53,7 @@ type wifiStater interface {
 }
 
 // NewWifiCollector returns a new Collector exposing Wifi statistics.
-func NewWifiCollector(logger log.Logger) (gateway.Collector, error) {
+func NewWifiCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	const (
 		subsystem = "wifi"
 	)
@@ -3,6 +3,7 @@
 package node
 
 import (
+	"fildr-cli/internal/config"
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fmt"
@@ -17,11 +18,11 @@ type xfsCollector struct {
 }
 
 func init() {
-	registerCollector("xfs", NewXFSCollector)
+	registerCollector("xfs", defaultEnabled, NewXFSCollector)
 }
 
 // NewXFSCollector returns a new Collector exposing XFS statistics.
-func NewXFSCollector(logger log.Logger) (gateway.Collector, error) {
+func NewXFSCollector(logger log.Logger, cfg config.Collector) (gateway.Collector, error) {
 	fs, err := xfs.NewFS(procPath, sysPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sysfs: %w", err)
@@ -0,0 +1,97 @@
+package pledge
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// 启动时从决策日志末尾读取最近的质押记录，检查间隔不小于 1 分钟，一小时内的记录远小于该大小
+const auditTailBytes = 256 << 10
+
+const (
+	actionPledge = "pledge"
+	actionDryRun = "dry-run"
+	actionSkip   = "skip"
+	actionError  = "error"
+)
+
+// 每次检查的决策，以 JSON 行写入决策日志
+type decision struct {
+	Time             time.Time         `json:"time"`
+	Miner            string            `json:"miner,omitempty"`
+	Action           string            `json:"action"`
+	Reasons          []string          `json:"reasons,omitempty"`
+	Sealing          int               `json:"sealing"`
+	FreeBytes        map[string]uint64 `json:"free_bytes,omitempty"`
+	AvailableWorkers int               `json:"available_workers"`
+	Balance          string            `json:"balance,omitempty"`
+	Error            string            `json:"error,omitempty"`
+}
+
+func writeAudit(path string, d decision) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// 读取决策日志中一小时内的质押和 dry-run 记录，重启后继续按 max-per-hour 限制
+func readRecent(path string, now time.Time) ([]time.Time, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - auditTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	b := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(b, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	// 从文件中间开始读取时跳过不完整的第一行
+	if offset > 0 {
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			b = b[i+1:]
+		}
+	}
+
+	var recent []time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64<<10), auditTailBytes)
+	for scanner.Scan() {
+		var d decision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			continue
+		}
+		if d.Action != actionPledge && d.Action != actionDryRun {
+			continue
+		}
+		if now.Sub(d.Time) < time.Hour && !d.Time.After(now) {
+			recent = append(recent, d.Time)
+		}
+	}
+	return recent, scanner.Err()
+}
@@ -0,0 +1,42 @@
+package pledge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadRecent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pledge")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "pledge-audit.log")
+	now := time.Now().Truncate(time.Second)
+
+	// 文件不存在时没有记录
+	recent, err := readRecent(path, now)
+	assert.NoError(t, err)
+	assert.Empty(t, recent)
+
+	require.NoError(t, writeAudit(path, decision{Time: now.Add(-2 * time.Hour), Action: actionPledge}))
+	require.NoError(t, writeAudit(path, decision{Time: now.Add(-30 * time.Minute), Action: actionPledge}))
+	require.NoError(t, writeAudit(path, decision{Time: now.Add(-20 * time.Minute), Action: actionSkip}))
+	require.NoError(t, writeAudit(path, decision{Time: now.Add(-10 * time.Minute), Action: actionError}))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, writeAudit(path, decision{Time: now.Add(-5 * time.Minute), Action: actionDryRun}))
+
+	recent, err = readRecent(path, now)
+	assert.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.True(t, recent[0].Equal(now.Add(-30*time.Minute)))
+	assert.True(t, recent[1].Equal(now.Add(-5*time.Minute)))
+}
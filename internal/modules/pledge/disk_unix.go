@@ -0,0 +1,14 @@
+// +build !windows
+
+package pledge
+
+import "syscall"
+
+// 非 root 用户可用的剩余空间
+func freeBytes(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
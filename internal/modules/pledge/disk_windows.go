@@ -0,0 +1,7 @@
+package pledge
+
+import "errors"
+
+func freeBytes(path string) (uint64, error) {
+	return 0, errors.New("free space is not supported on windows")
+}
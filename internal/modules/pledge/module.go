@@ -0,0 +1,235 @@
+package pledge
+
+import (
+	"context"
+	"fildr-cli/internal/config"
+	"fildr-cli/internal/log"
+	"fildr-cli/internal/module"
+	"fildr-cli/internal/modules/lotus"
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types"
+	"path/filepath"
+	"time"
+)
+
+var _ module.Module = (*PledgeModule)(nil)
+
+// 自动质押扇区，按间隔检查封装任务、磁盘、worker 和钱包余额，满足策略时调用 PledgeSector
+type PledgeModule struct {
+	logger log.Logger
+	cfg    config.Pledge
+	policy policy
+	audit  string
+	cancel context.CancelFunc
+	// 每次检查调用 lotus 接口的超时时间
+	timeout time.Duration
+
+	// 质押的 miner，连接与 lotus 收集器共享
+	node   *lotus.Miner
+	recent []time.Time
+}
+
+func New(ctx context.Context) (*PledgeModule, error) {
+	logger := log.From(ctx)
+	return &PledgeModule{logger: logger}, nil
+}
+
+func (mod *PledgeModule) Name() string {
+	return "pledge"
+}
+
+func (mod *PledgeModule) Start() error {
+	cfg := config.Get()
+	if !cfg.Pledge.Enable {
+		return nil
+	}
+	if len(cfg.Lotus.EffectiveMiners()) == 0 {
+		mod.logger.Warnf("auto pledge requires lotus.miner, pledge is disabled")
+		return nil
+	}
+
+	node, err := lotus.SharedMiner(cfg.Pledge.Miner)
+	if err != nil {
+		return fmt.Errorf("auto pledge: %w", err)
+	}
+	if cfg.Pledge.Miner == "" && len(cfg.Lotus.EffectiveMiners()) > 1 {
+		mod.logger.Warnf("multiple lotus miners are configured, auto pledge only applies to %s, set pledge.miner to choose one", node.Name())
+	}
+
+	mod.cfg = cfg.Pledge
+	mod.node = node
+	mod.timeout = node.Timeout()
+	mod.policy = policy{
+		maxSealing:          cfg.Pledge.MaxSealing,
+		minFreeBytes:        cfg.Pledge.MinFreeBytes,
+		minAvailableWorkers: cfg.Pledge.MinAvailableWorkers,
+		maxPerHour:          cfg.Pledge.MaxPerHour,
+	}
+	if cfg.Pledge.MinBalance != "" {
+		fil, err := types.ParseFIL(cfg.Pledge.MinBalance)
+		if err != nil {
+			return fmt.Errorf("invalid pledge min-balance: %w", err)
+		}
+		mod.policy.minBalance = types.BigInt(fil)
+	}
+
+	mod.audit = cfg.Pledge.AuditLog
+	if mod.audit == "" {
+		dir, err := config.Dir()
+		if err != nil {
+			return err
+		}
+		mod.audit = filepath.Join(dir, "pledge-audit.log")
+	}
+
+	// 重启不重置每小时的质押次数
+	recent, err := readRecent(mod.audit, time.Now())
+	if err != nil {
+		mod.logger.Warnf("read pledge audit log err: %v", err)
+	}
+	mod.recent = recent
+
+	interval := cfg.Pledge.Interval
+	if interval < time.Minute {
+		interval = config.DefaultPledgeInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mod.cancel = cancel
+	go mod.run(ctx, interval)
+
+	mod.logger.Infof("auto pledge started, check every %s, dry-run: %v", interval, cfg.Pledge.DryRun)
+	return nil
+}
+
+func (mod *PledgeModule) Stop() {
+	if mod.cancel != nil {
+		mod.cancel()
+	}
+}
+
+func (mod *PledgeModule) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d := mod.check(ctx)
+			if err := writeAudit(mod.audit, d); err != nil {
+				mod.logger.Warnf("write pledge audit log err: %v", err)
+			}
+		}
+	}
+}
+
+// 检查一次，需要时质押扇区，返回本次的决策
+func (mod *PledgeModule) check(ctx context.Context) decision {
+	now := time.Now()
+	d := decision{Time: now}
+
+	callCtx, cancel := context.WithTimeout(ctx, mod.timeout)
+	miner, client, maddr, err := mod.node.Clients(callCtx)
+	cancel()
+	if err != nil {
+		d.Action = actionError
+		d.Error = err.Error()
+		mod.logger.Warnf("auto pledge connect lotus err: %v", err)
+		return d
+	}
+	d.Miner = maddr.String()
+
+	s, err := mod.state(ctx, now, miner, client, maddr)
+	if err != nil {
+		d.Action = actionError
+		d.Error = err.Error()
+		mod.logger.Warnf("auto pledge get miner state err: %v", err)
+		return d
+	}
+	d.Sealing = s.sealing
+	d.FreeBytes = s.freeBytes
+	d.AvailableWorkers = s.availableWorkers
+	if s.balance.Int != nil {
+		d.Balance = types.FIL(s.balance).String()
+	}
+
+	if d.Reasons = mod.policy.check(s); len(d.Reasons) > 0 {
+		d.Action = actionSkip
+		mod.logger.Debugf("auto pledge skipped: %v", d.Reasons)
+		return d
+	}
+
+	if mod.cfg.DryRun {
+		mod.recent = append(mod.recent, now)
+		d.Action = actionDryRun
+		mod.logger.Infof("auto pledge dry-run, a sector would be pledged")
+		return d
+	}
+
+	callCtx, cancel = context.WithTimeout(ctx, mod.timeout)
+	defer cancel()
+	if err := miner.PledgeSector(callCtx); err != nil {
+		d.Action = actionError
+		d.Error = err.Error()
+		mod.logger.Warnf("pledge sector err: %v", err)
+		return d
+	}
+	// 质押失败不占用每小时的次数
+	mod.recent = append(mod.recent, now)
+	d.Action = actionPledge
+	mod.logger.Infof("pledged a sector")
+	return d
+}
+
+// 采集策略需要的状态，扇区状态单独计时，扇区很多时不影响其他接口
+func (mod *PledgeModule) state(ctx context.Context, now time.Time, miner *lotus.MinerClient, client *lotus.Client, maddr address.Address) (state, error) {
+	mod.recent = pruneRecent(mod.recent, now)
+	s := state{freeBytes: make(map[string]uint64), recent: mod.recent}
+
+	scanCtx, cancel := context.WithTimeout(ctx, mod.timeout)
+	sealing, complete, err := countSealing(scanCtx, miner, mod.cfg.ScanSectors)
+	cancel()
+	if err != nil {
+		return s, err
+	}
+	s.sealing = sealing
+	s.scanIncomplete = !complete
+	if !complete {
+		mod.logger.Warnf("auto pledge sector status scan timed out after %s, lower pledge.scan-sectors", mod.timeout)
+	}
+
+	for _, path := range mod.cfg.StoragePaths {
+		free, err := freeBytes(path)
+		if err != nil {
+			return s, fmt.Errorf("stat %s: %w", path, err)
+		}
+		s.freeBytes[path] = free
+	}
+
+	ctx, cancel = context.WithTimeout(ctx, mod.timeout)
+	defer cancel()
+	workers, err := miner.WorkerStats(ctx)
+	if err != nil {
+		return s, fmt.Errorf("get worker stats: %w", err)
+	}
+	for _, w := range workers {
+		if w.CpuUse < w.Info.Resources.CPUs {
+			s.availableWorkers++
+		}
+	}
+
+	if mod.policy.minBalance.Int != nil {
+		info, err := client.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return s, fmt.Errorf("get miner info: %w", err)
+		}
+		balance, err := client.WalletBalance(ctx, info.Worker)
+		if err != nil {
+			return s, fmt.Errorf("get worker balance: %w", err)
+		}
+		s.balance = balance
+	}
+	return s, nil
+}
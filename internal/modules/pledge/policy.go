@@ -0,0 +1,88 @@
+package pledge
+
+import (
+	"fmt"
+	"github.com/filecoin-project/lotus/chain/types"
+	"time"
+)
+
+// 正在封装的扇区状态，包括新版本 lotus 增加的状态；失败的扇区不再占用封装资源，不计入封装中
+var sealingStates = map[string]bool{
+	"Empty":                 true,
+	"WaitDeals":             true,
+	"Packing":               true,
+	"AddPiece":              true,
+	"GetTicket":             true,
+	"PreCommit1":            true,
+	"PreCommit2":            true,
+	"PreCommitting":         true,
+	"PreCommitWait":         true,
+	"SubmitPreCommitBatch":  true,
+	"PreCommitBatchWait":    true,
+	"WaitSeed":              true,
+	"Committing":            true,
+	"CommitFinalize":        true,
+	"SubmitCommit":          true,
+	"CommitWait":            true,
+	"SubmitCommitAggregate": true,
+	"CommitAggregateWait":   true,
+	"FinalizeSector":        true,
+}
+
+// 质押策略，由配置生成
+type policy struct {
+	maxSealing          int
+	minFreeBytes        uint64
+	minAvailableWorkers int
+	minBalance          types.BigInt
+	maxPerHour          int
+}
+
+// 一次检查时采集到的矿工状态
+type state struct {
+	sealing int
+	// 扇区状态没有在超时前查询完，sealing 可能偏小
+	scanIncomplete   bool
+	freeBytes        map[string]uint64
+	availableWorkers int
+	balance          types.BigInt
+	// 最近一小时内的质押时间
+	recent []time.Time
+}
+
+// 返回不满足的条件，为空时可以质押
+func (p policy) check(s state) []string {
+	var reasons []string
+	if p.maxSealing > 0 && s.sealing >= p.maxSealing {
+		reasons = append(reasons, fmt.Sprintf("%d sectors sealing, max %d", s.sealing, p.maxSealing))
+	}
+	if p.maxSealing > 0 && s.scanIncomplete {
+		reasons = append(reasons, "sector status scan did not finish in time")
+	}
+	for path, free := range s.freeBytes {
+		if free < p.minFreeBytes {
+			reasons = append(reasons, fmt.Sprintf("%s has %d bytes free, min %d", path, free, p.minFreeBytes))
+		}
+	}
+	if s.availableWorkers < p.minAvailableWorkers {
+		reasons = append(reasons, fmt.Sprintf("%d workers available, min %d", s.availableWorkers, p.minAvailableWorkers))
+	}
+	if p.minBalance.Int != nil && types.BigCmp(s.balance, p.minBalance) < 0 {
+		reasons = append(reasons, fmt.Sprintf("worker balance %s, min %s", types.FIL(s.balance), types.FIL(p.minBalance)))
+	}
+	if p.maxPerHour > 0 && len(s.recent) >= p.maxPerHour {
+		reasons = append(reasons, fmt.Sprintf("%d pledges in the last hour, max %d", len(s.recent), p.maxPerHour))
+	}
+	return reasons
+}
+
+// 去掉一小时以前的质押记录
+func pruneRecent(recent []time.Time, now time.Time) []time.Time {
+	kept := recent[:0]
+	for _, t := range recent {
+		if now.Sub(t) < time.Hour {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,63 @@
+package pledge
+
+import (
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	minBalance, err := types.ParseFIL("1")
+	assert.NoError(t, err)
+	p := policy{
+		maxSealing:          2,
+		minFreeBytes:        100,
+		minAvailableWorkers: 1,
+		minBalance:          types.BigInt(minBalance),
+		maxPerHour:          1,
+	}
+
+	balance, _ := types.ParseFIL("2")
+	ok := state{
+		sealing:          1,
+		freeBytes:        map[string]uint64{"/data": 200},
+		availableWorkers: 1,
+		balance:          types.BigInt(balance),
+	}
+	assert.Empty(t, p.check(ok))
+
+	low, _ := types.ParseFIL("0.5")
+	bad := state{
+		sealing:          2,
+		freeBytes:        map[string]uint64{"/data": 50},
+		availableWorkers: 0,
+		balance:          types.BigInt(low),
+		recent:           []time.Time{time.Now()},
+	}
+	assert.Len(t, p.check(bad), 5)
+
+	// 扇区状态没有查询完时不质押
+	incomplete := ok
+	incomplete.scanIncomplete = true
+	assert.Len(t, p.check(incomplete), 1)
+
+	// 未配置的条件不检查
+	assert.Empty(t, policy{}.check(state{}))
+}
+
+func TestSealingStates(t *testing.T) {
+	for _, st := range []string{"Packing", "PreCommit1", "WaitSeed", "CommitWait", "FinalizeSector"} {
+		assert.True(t, sealingStates[st], st)
+	}
+	// 失败和已完成的扇区不占用封装资源
+	for _, st := range []string{"Proving", "SealPreCommit1Failed", "CommitFailed", "PackingFailed", "DealsExpired", "FailedUnrecoverable", "Removed"} {
+		assert.False(t, sealingStates[st], st)
+	}
+}
+
+func TestPruneRecent(t *testing.T) {
+	now := time.Now()
+	recent := []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Minute), now}
+	assert.Equal(t, []time.Time{now.Add(-time.Minute), now}, pruneRecent(recent, now))
+}
@@ -0,0 +1,37 @@
+package pledge
+
+import (
+	"context"
+	"fildr-cli/internal/modules/lotus"
+	"fmt"
+	"sort"
+)
+
+// 从编号最大的扇区开始统计封装中的扇区，最多查询 limit 个，limit 不大于 0 时查询全部；
+// context 超时后返回已统计的数量，complete 为 false
+func countSealing(ctx context.Context, miner *lotus.MinerClient, limit int) (sealing int, complete bool, err error) {
+	sectors, err := miner.SectorsList(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("list sectors: %w", err)
+	}
+	sort.Slice(sectors, func(i, j int) bool { return sectors[i] > sectors[j] })
+	if limit > 0 && len(sectors) > limit {
+		sectors = sectors[:limit]
+	}
+	for _, sn := range sectors {
+		if ctx.Err() != nil {
+			return sealing, false, nil
+		}
+		info, err := miner.SectorsStatus(ctx, sn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return sealing, false, nil
+			}
+			return sealing, false, fmt.Errorf("get sector %d status: %w", sn, err)
+		}
+		if sealingStates[string(info.State)] {
+			sealing++
+		}
+	}
+	return sealing, true, nil
+}
@@ -0,0 +1,68 @@
+package pledge
+
+import (
+	"context"
+	"fildr-cli/internal/modules/lotus"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// 模拟有大量扇区的 miner，编号最大的 10 个在封装中，之前的 10 个封装失败
+func stubMiner(total int, calls *[]abi.SectorNumber) *lotus.MinerClient {
+	return &lotus.MinerClient{
+		SectorsList: func(ctx context.Context) ([]abi.SectorNumber, error) {
+			sectors := make([]abi.SectorNumber, total)
+			for i := range sectors {
+				sectors[i] = abi.SectorNumber(i)
+			}
+			return sectors, nil
+		},
+		SectorsStatus: func(ctx context.Context, sn abi.SectorNumber) (api.SectorInfo, error) {
+			*calls = append(*calls, sn)
+			if err := ctx.Err(); err != nil {
+				return api.SectorInfo{}, err
+			}
+			info := api.SectorInfo{SectorID: sn, State: "Proving"}
+			switch {
+			case int(sn) >= total-10:
+				info.State = "PreCommit1"
+			case int(sn) >= total-20:
+				info.State = "SealPreCommit1Failed"
+			}
+			return info, nil
+		},
+	}
+}
+
+func TestCountSealing(t *testing.T) {
+	var calls []abi.SectorNumber
+	sealing, complete, err := countSealing(context.Background(), stubMiner(5000, &calls), 500)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, 10, sealing)
+	// 只查询编号最大的扇区
+	assert.Len(t, calls, 500)
+	assert.Equal(t, abi.SectorNumber(4999), calls[0])
+	assert.Equal(t, abi.SectorNumber(4500), calls[499])
+}
+
+func TestCountSealingTimeout(t *testing.T) {
+	var calls []abi.SectorNumber
+	ctx, cancel := context.WithCancel(context.Background())
+	miner := stubMiner(5000, &calls)
+	status := miner.SectorsStatus
+	miner.SectorsStatus = func(ctx context.Context, sn abi.SectorNumber) (api.SectorInfo, error) {
+		if len(calls) == 5 {
+			cancel()
+		}
+		return status(ctx, sn)
+	}
+
+	sealing, complete, err := countSealing(ctx, miner, 0)
+	assert.NoError(t, err)
+	assert.False(t, complete)
+	assert.Equal(t, 5, sealing)
+	assert.Len(t, calls, 6)
+}
@@ -6,13 +6,17 @@ import (
 	"fildr-cli/internal/gateway"
 	"fildr-cli/internal/log"
 	"fildr-cli/internal/module"
+	"fildr-cli/internal/modules/logs"
 	"fildr-cli/internal/modules/lotus"
 	"fildr-cli/internal/modules/node"
+	"fildr-cli/internal/modules/pledge"
 	"fmt"
 )
 
 type Options struct {
-	Context string
+	Context   string
+	Version   string
+	GitCommit string
 }
 
 type Runner struct {
@@ -32,6 +36,8 @@ func NewRunner(ctx context.Context, logger log.Logger, options Options) (*Runner
 		return nil, err
 	}
 
+	gateway.SetBuildInfo(options.Version, options.GitCommit)
+
 	moduleManager, err := initModuleManager(logger)
 	if err != nil {
 		return nil, fmt.Errorf("init module manager: %w", err)
@@ -49,7 +55,9 @@ func NewRunner(ctx context.Context, logger log.Logger, options Options) (*Runner
 		}
 	}
 
-	gateway.Run(ctx)
+	if err := gateway.Run(ctx); err != nil {
+		return nil, fmt.Errorf("running gateway: %w", err)
+	}
 
 	return &r, nil
 }
@@ -69,7 +77,7 @@ func (r *Runner) Start(ctx context.Context, startupCh, shutdownCh chan bool) {
 }
 
 func (r *Runner) Stop(ctx context.Context) {
-
+	r.moduleManager.Unload()
 }
 
 func initModuleManager(logger log.Logger) (*module.Manager, error) {
@@ -94,8 +102,20 @@ func initModules(ctx context.Context) ([]module.Module, error) {
 		return nil, fmt.Errorf("initialize lotus collector module: %v", err)
 	}
 
+	pledgeModule, err := pledge.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initialize pledge module: %v", err)
+	}
+
+	logsModule, err := logs.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initialize logs module: %v", err)
+	}
+
 	list = append(list, nodeCollector)
 	list = append(list, lotusCollector)
+	list = append(list, pledgeModule)
+	list = append(list, logsModule)
 
 	return list, nil
 }